@@ -6,22 +6,118 @@ import (
 	"strings"
 )
 
-// Processes the annotations aquired from the 'bin' tag.
-// Removes optional indentation spaces, splits them by comma and removes empty entries.
+// Processes the annotations aquired from the 'bin' tag according to the grammar of parseTag.
+// Resolves the quotes and escapes of the values and removes the indentation and empty entries.
 //
-// Returns a string array of the annotations and a bool with true if there are actual entries in it.
-func getAnnotationList(tag string) ([]string, bool) {
-
-	tag = strings.Replace(tag, " ", "", -1)
-	var annotations = strings.Split(tag, ",")
-	var annotationsFiltered []string
-	for i := range annotations {
-		if len(annotations[i]) > 0 {
-			annotationsFiltered = append(annotationsFiltered, annotations[i])
+// Returns a string array of the annotations and a bool with true if there are actual entries in it,
+// or an error if the tag can't be parsed.
+func getAnnotationList(tag string) ([]string, bool, error) {
+
+	var annotations, err = parseTag(tag)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return annotations, len(annotations) > 0, nil
+}
+
+// Sign conventions of the 'sign' annotation.
+const (
+	signLeading  = "leading"
+	signTrailing = "trailing"
+	signNone     = "none"
+	signParen    = "paren"
+)
+
+// Rounding modes of the 'round' annotation.
+const (
+	roundHalfUp   = "half-up"
+	roundHalfEven = "half-even"
+	roundDown     = "down"
+)
+
+// The value of the 'length' annotation for the length of the whole struct.
+const lengthSelf = "self"
+
+// The names of the annotations used without a value.
+var flagAnnotations = []string{"trim", "padspace", "forcesign", "blankzero", "exact", "optional", "allowshort", "primary", "strictsize", "filldefault"}
+
+// The names of the annotations used in the form of "<name>:<value>".
+var valueAnnotations = []string{"array", "precision", "sign", "null", "nullflag", "round", "scale", "offset", "lenprefix", "const", "if", "overlay", "checksum", "length", "sep", "terminator", "default"}
+
+// Checks if the annotation is an address or one of the known flag or value annotations and returns a bool accordingly.
+//
+// NOTE: Doesn't check the value of the annotation.
+func isKnownAnnotation(annotation string) bool {
+
+	if isValidAddressAnnotation(annotation) || sliceContainsString(flagAnnotations, annotation) {
+		return true
+	}
+
+	if idx := strings.Index(annotation, ":"); idx > 0 {
+		return sliceContainsString(valueAnnotations, annotation[:idx])
+	}
+
+	return false
+}
+
+// Returns the value of the first annotation with the provided 'key' in the form of "<key>:<value>"
+// along with a bool which is true if found. (', ok' idiom)
+func getAnnotationValue(annotationList []string, key string) (string, bool) {
+	for _, val := range annotationList {
+		if strings.HasPrefix(val, key+":") {
+			return val[len(key)+1:], true
 		}
 	}
+	return "", false
+}
 
-	return annotationsFiltered, len(annotationsFiltered) > 0
+// Returns the value of the 'const' annotation and a bool which is true if found. (', ok' idiom)
+func getConstantFromAnnotation(annotationList []string) (string, bool) {
+	return getAnnotationValue(annotationList, "const")
+}
+
+// Returns the value of the 'sep' annotation and a bool which is true if found. (', ok' idiom)
+func getSeparatorFromAnnotation(annotationList []string) (string, bool) {
+	return getAnnotationValue(annotationList, "sep")
+}
+
+// Finds the 'terminator' annotation in the form of "terminator:<string>" and returns the terminator, along with a bool
+// which is true if found. Control characters are given with escapes in a quoted value, e.g. "terminator:'\x17'".
+// Gives an error if the string is empty.
+func getTerminatorFromAnnotation(annotationList []string) (string, bool, error) {
+
+	var terminator, hasTerminator = getAnnotationValue(annotationList, "terminator")
+	if !hasTerminator {
+		return "", false, nil
+	}
+
+	if terminator == "" {
+		return "", false, newInvalidTerminatorError(terminator)
+	}
+
+	return terminator, true, nil
+}
+
+// Finds the 'if' annotation in the form of "if:<field_name>=<value>" or "if:<field_name>!=<value>".
+//
+// Returns the field name, the value, a bool which is true if the condition is negated and a bool which is true if found.
+// Gives an error if the annotation has no field name or comparison.
+func getConditionFromAnnotation(annotationList []string) (string, string, bool, bool, error) {
+
+	var condition, hasCondition = getAnnotationValue(annotationList, "if")
+	if !hasCondition {
+		return "", "", false, false, nil
+	}
+
+	if idx := strings.Index(condition, "!="); idx > 0 {
+		return condition[:idx], condition[idx+2:], true, true, nil
+	}
+	if idx := strings.Index(condition, "="); idx > 0 {
+		return condition[:idx], condition[idx+1:], false, true, nil
+	}
+
+	return "", "", false, true, newInvalidConditionError(condition)
 }
 
 // Checks the annotation array if the 'trim' annotation is in it and returns a bool accordingly.
@@ -29,6 +125,31 @@ func hasAnnotationTrim(annotationList []string) bool {
 	return sliceContainsString(annotationList, "trim")
 }
 
+// Checks the annotation array if the 'optional' annotation is in it and returns a bool accordingly.
+func hasAnnotationOptional(annotationList []string) bool {
+	return sliceContainsString(annotationList, "optional")
+}
+
+// Checks the annotation array if the 'allowshort' annotation is in it and returns a bool accordingly.
+func hasAnnotationAllowShort(annotationList []string) bool {
+	return sliceContainsString(annotationList, "allowshort")
+}
+
+// Checks the annotation array if the 'primary' annotation is in it and returns a bool accordingly.
+func hasAnnotationPrimary(annotationList []string) bool {
+	return sliceContainsString(annotationList, "primary")
+}
+
+// Checks the annotation array if the 'filldefault' annotation is in it and returns a bool accordingly.
+func hasAnnotationFillDefault(annotationList []string) bool {
+	return sliceContainsString(annotationList, "filldefault")
+}
+
+// Checks the annotation array if the 'strictsize' annotation is in it and returns a bool accordingly.
+func hasAnnotationStrictSize(annotationList []string) bool {
+	return sliceContainsString(annotationList, "strictsize")
+}
+
 // Checks the annotation array if the 'padspace' annotation is in it and returns a bool accordingly.
 func hasAnnotationPadspace(annotationList []string) bool {
 	return sliceContainsString(annotationList, "padspace")
@@ -39,6 +160,29 @@ func hasAnnotationForceSign(annotationList []string) bool {
 	return sliceContainsString(annotationList, "forcesign")
 }
 
+// Checks the annotation array if the 'exact' annotation is in it and returns a bool accordingly.
+func hasAnnotationExact(annotationList []string) bool {
+	return sliceContainsString(annotationList, "exact")
+}
+
+// Checks the annotation array if the 'blankzero' annotation is in it and returns a bool accordingly.
+func hasAnnotationBlankZero(annotationList []string) bool {
+	return sliceContainsString(annotationList, "blankzero")
+}
+
+// Finds and returns the values of all 'null' annotations in the annotation list.
+func getNullAnnotations(annotationList []string) []string {
+
+	var nullPatterns []string
+	for _, val := range annotationList {
+		if strings.HasPrefix(val, "null:") {
+			nullPatterns = append(nullPatterns, val[len("null:"):])
+		}
+	}
+
+	return nullPatterns
+}
+
 // Finds and returns the 'array' annotation in the annotation list along with a bool which value is true if found.
 func getArrayAnnotation(annotationList []string) (string, bool) {
 
@@ -65,6 +209,14 @@ func isArrayTypeTerminator(arrayAnnotation string) bool {
 	return false
 }
 
+// Checks the provided 'array' annotation if it's a fill type which repeats until the end of the record and returns a bool accordingly.
+func isArrayTypeFill(arrayAnnotation string) bool {
+
+	var vals = strings.Split(arrayAnnotation, ":")
+
+	return len(vals) == 2 && vals[1] == "fill"
+}
+
 // Checks the provided 'array' annotation it it has a valid integer value and returns it along with a true boolean value.
 //
 // Note: Will return a false on missing or non-integer values in which case the value should not be used. (', ok' idiom)
@@ -86,7 +238,7 @@ func getArrayFixedSize(arrayAnnotation string) (int, bool) {
 // Returns the 'array' annotation's value if found along with a bool accordingly. (', ok' idiom)
 //
 // Note: This will return true even for a terminated type. Although this library won't restrict you
-// to use a field named 'terminator' for an array size, you should refrain from it and check for a terminated type first.
+// to use a field named 'terminator' or 'fill' for an array size, you should refrain from it and check for those types first.
 func getArraySizeFieldName(arrayAnnotation string) (string, bool) {
 
 	var vals = strings.Split(arrayAnnotation, ":")
@@ -118,21 +270,28 @@ func getAddressAnnotation(annotationList []string) (int, int, bool, error) {
 	return -1, -1, false, nil
 }
 
+// Checks if the address annotation has an absolute position counted from the start of the message
+// in the form of "@<absolute_position>:<relative_length>" and returns a bool accordingly.
+func isRootRelativeAddress(annotationList []string) bool {
+	for _, val := range annotationList {
+		if isValidAddressAnnotation(val) {
+			return strings.HasPrefix(val, "@")
+		}
+	}
+	return false
+}
+
 // isValidAddressAnnotation - verify the valdity of an address annotation
 // returns
-//   - true when the string matches /^\d*:\d+$/
+//   - true when the string matches /^(@\d+|\d*):\d+$/
 //   - false otherwise
 func isValidAddressAnnotation(str string) bool {
-
-	expr, err := regexp.Compile(`^\d*:\d+$`)
-	if err != nil {
-		panic("Invalid Expression in regexp (this error should never occur)")
-	}
-	matched := expr.Match([]byte(str))
-
-	return matched
+	return addressAnnotationExpr.MatchString(str)
 }
 
+// The expression of a valid address annotation compiled once.
+var addressAnnotationExpr = regexp.MustCompile(`^(@\d+|\d*):\d+$`)
+
 // Read an address annotation with format "absolute:length" or ":length".
 // Gives an error if the values aren't valid integers.
 func readAddressAnnotation(str string) (int, int, error) {
@@ -140,7 +299,7 @@ func readAddressAnnotation(str string) (int, int, error) {
 	var length = -1
 	var err = error(nil)
 
-	parts := strings.Split(str, ":")
+	parts := strings.Split(strings.TrimPrefix(str, "@"), ":")
 
 	if len(parts) >= 1 { // the 1st part is the absolute address
 		if parts[0] != "" {
@@ -185,3 +344,137 @@ func getPrecisionFromAnnotation(annotationList []string) (int, error) {
 
 	return -1, nil
 }
+
+// Finds and returns the sign convention from the annotation list. The default value is 'leading'.
+// Gives an error if the value is not one of 'leading', 'trailing', 'none' or 'paren'.
+func getSignConventionFromAnnotation(annotationList []string) (string, error) {
+
+	var sign, hasSign = getAnnotationValue(annotationList, "sign")
+	if !hasSign {
+		return signLeading, nil
+	}
+
+	switch sign {
+	case signLeading, signTrailing, signNone, signParen:
+		return sign, nil
+	}
+
+	return signLeading, newInvalidSignConventionError(sign)
+}
+
+// Finds and returns the rounding mode from the annotation list along with a bool which is true if found.
+// Gives an error if the value is not one of 'half-up', 'half-even' or 'down'.
+func getRoundingModeFromAnnotation(annotationList []string) (string, bool, error) {
+
+	var roundingMode, hasRoundingMode = getAnnotationValue(annotationList, "round")
+	if !hasRoundingMode {
+		return "", false, nil
+	}
+
+	switch roundingMode {
+	case roundHalfUp, roundHalfEven, roundDown:
+		return roundingMode, true, nil
+	}
+
+	return "", false, newInvalidRoundingModeError(roundingMode)
+}
+
+// Finds and returns the 'scale' and 'offset' values from the annotation list along with a bool which is true if any of them was found.
+// The default values are '1' for the scale and '0' for the offset.
+// Gives an error if a value is not a valid float or the scale is zero.
+func getScalingFromAnnotation(annotationList []string) (float64, float64, bool, error) {
+
+	var scale, offset = 1.0, 0.0
+	var isScaled = false
+
+	if scaleStr, hasScale := getAnnotationValue(annotationList, "scale"); hasScale {
+		var err error
+		if scale, err = strconv.ParseFloat(scaleStr, 64); err != nil || scale == 0 {
+			return 1, 0, false, newInvalidScalingError("scale", scaleStr)
+		}
+		isScaled = true
+	}
+
+	if offsetStr, hasOffset := getAnnotationValue(annotationList, "offset"); hasOffset {
+		var err error
+		if offset, err = strconv.ParseFloat(offsetStr, 64); err != nil {
+			return 1, 0, false, newInvalidScalingError("offset", offsetStr)
+		}
+		isScaled = true
+	}
+
+	return scale, offset, isScaled, nil
+}
+
+// Finds and returns the width of the 'lenprefix' annotation and a bool which is true if the prefix is binary instead of ASCII digits,
+// along with a bool which is true if found. The annotation has the form of "lenprefix:<width>" or "lenprefix:<width>:<ascii|binary>".
+// Gives an error if the width is not a positive integer, a binary prefix is wider than an int or the encoding is unknown.
+func getLengthPrefixFromAnnotation(annotationList []string) (int, bool, bool, error) {
+
+	var lenPrefix, hasLenPrefix = getAnnotationValue(annotationList, "lenprefix")
+	if !hasLenPrefix {
+		return 0, false, false, nil
+	}
+
+	var vals = strings.Split(lenPrefix, ":")
+	if len(vals) > 2 {
+		return 0, false, false, newInvalidLengthPrefixError(lenPrefix)
+	}
+
+	var width, err = strconv.Atoi(vals[0])
+	if err != nil || width <= 0 {
+		return 0, false, false, newInvalidLengthPrefixError(lenPrefix)
+	}
+
+	var isBinary = false
+	if len(vals) == 2 {
+		switch vals[1] {
+		case "ascii":
+		case "binary":
+			isBinary = true
+		default:
+			return 0, false, false, newInvalidLengthPrefixError(lenPrefix)
+		}
+	}
+	if isBinary && width > strconv.IntSize/8 { // the length must fit in an int
+		return 0, false, false, newInvalidLengthPrefixError(lenPrefix)
+	}
+
+	return width, isBinary, true, nil
+}
+
+// Finds the 'checksum' annotation in the form of "checksum:<algorithm>:<from>-<to>" where 'to' is optional.
+//
+// Returns the algorithm, the range of the bytes ('to' is -1 if open) and a bool which is true if found.
+// Gives an error if the range is not valid.
+func getChecksumFromAnnotation(annotationList []string) (string, int, int, bool, error) {
+
+	var checksum, hasChecksum = getAnnotationValue(annotationList, "checksum")
+	if !hasChecksum {
+		return "", 0, -1, false, nil
+	}
+
+	var vals = strings.Split(checksum, ":")
+	if len(vals) != 2 || vals[0] == "" {
+		return "", 0, -1, false, newInvalidChecksumError(checksum)
+	}
+
+	var bounds = strings.Split(vals[1], "-")
+	if len(bounds) != 2 {
+		return "", 0, -1, false, newInvalidChecksumError(checksum)
+	}
+
+	var from, err = strconv.Atoi(bounds[0])
+	if err != nil || from < 0 {
+		return "", 0, -1, false, newInvalidChecksumError(checksum)
+	}
+
+	var to = -1
+	if bounds[1] != "" {
+		if to, err = strconv.Atoi(bounds[1]); err != nil || to < from {
+			return "", 0, -1, false, newInvalidChecksumError(checksum)
+		}
+	}
+
+	return vals[0], from, to, true, nil
+}
@@ -0,0 +1,113 @@
+package binfile
+
+import (
+	"sync"
+)
+
+// A ChecksumAlgorithm computes the check value of the provided bytes.
+type ChecksumAlgorithm func(data []byte) uint64
+
+// The names of the built-in checksum algorithms.
+const (
+	ChecksumSum8       = "sum8"
+	ChecksumXor        = "xor"
+	ChecksumCRC16      = "crc16"
+	ChecksumCRC16CCITT = "crc16-ccitt"
+)
+
+// Holds the checksum algorithms by name. Guarded by checksumAlgorithmsMutex.
+var checksumAlgorithms = map[string]ChecksumAlgorithm{
+	ChecksumSum8:       checksumSum8,
+	ChecksumXor:        checksumXor,
+	ChecksumCRC16:      checksumCRC16,
+	ChecksumCRC16CCITT: checksumCRC16CCITT,
+}
+var checksumAlgorithmsMutex sync.RWMutex
+
+// Registers a checksum algorithm under the provided name, so it can be used in the 'checksum' annotation.
+// An algorithm already registered under the name is replaced. It's safe for concurrent use.
+func RegisterChecksumAlgorithm(name string, algorithm ChecksumAlgorithm) {
+	checksumAlgorithmsMutex.Lock()
+	defer checksumAlgorithmsMutex.Unlock()
+	checksumAlgorithms[name] = algorithm
+}
+
+// Returns the checksum algorithm registered under the provided name and a bool accordingly. (', ok' idiom)
+func getChecksumAlgorithm(name string) (ChecksumAlgorithm, bool) {
+	checksumAlgorithmsMutex.RLock()
+	defer checksumAlgorithmsMutex.RUnlock()
+	var algorithm, isRegistered = checksumAlgorithms[name]
+	return algorithm, isRegistered
+}
+
+// Computes the check value of the 'checksum' annotation of the field over 'structBytes', which are the bytes of the
+// struct containing the field. An open range ends at 'fieldStartByte', the start of the field in the struct.
+//
+// Returns the check value or an error if the algorithm isn't registered or the range is out of the struct.
+func computeChecksum(structBytes []byte, fieldStartByte int, field *fieldPlan) (uint64, error) {
+
+	var algorithm, isRegistered = getChecksumAlgorithm(field.checksumAlgorithm)
+	if !isRegistered {
+		return 0, newUnknownChecksumAlgorithmError(field.checksumAlgorithm)
+	}
+
+	var toByte = field.checksumTo
+	if toByte == -1 {
+		toByte = fieldStartByte
+	}
+	if field.checksumFrom > toByte || toByte > len(structBytes) {
+		return 0, newReadingOutOfBoundsError(field.checksumFrom, toByte, len(structBytes))
+	}
+
+	return algorithm(structBytes[field.checksumFrom:toByte]), nil
+}
+
+// The sum of the bytes modulo 256.
+func checksumSum8(data []byte) uint64 {
+	var sum byte
+	for _, val := range data {
+		sum += val
+	}
+	return uint64(sum)
+}
+
+// The bytes combined with exclusive or.
+func checksumXor(data []byte) uint64 {
+	var xor byte
+	for _, val := range data {
+		xor ^= val
+	}
+	return uint64(xor)
+}
+
+// CRC-16/ARC (also known as CRC-16/IBM): reflected polynomial 0x8005, initial value 0.
+func checksumCRC16(data []byte) uint64 {
+	var crc uint16
+	for _, val := range data {
+		crc ^= uint16(val)
+		for bit := 0; bit < 8; bit++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return uint64(crc)
+}
+
+// CRC-16/CCITT-FALSE: polynomial 0x1021, initial value 0xFFFF.
+func checksumCRC16CCITT(data []byte) uint64 {
+	var crc uint16 = 0xFFFF
+	for _, val := range data {
+		crc ^= uint16(val) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return uint64(crc)
+}
@@ -0,0 +1,74 @@
+package binfile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+//-Checksum--------------------------------------------------------------------
+
+type testChecksumCustom struct {
+	Data   string `bin:":5"`
+	Length int    `bin:":2,checksum:testlength:0-"`
+}
+
+type testChecksumUnknown struct {
+	Data  string `bin:":5"`
+	Check string `bin:":2,checksum:unknown:0-"`
+}
+
+func TestChecksumAlgorithms(t *testing.T) {
+
+	var data = []byte("123456789")
+
+	assert.Equal(t, uint64(0xDD), checksumSum8(data))
+	assert.Equal(t, uint64(0x31), checksumXor(data))
+	assert.Equal(t, uint64(0xBB3D), checksumCRC16(data))
+	assert.Equal(t, uint64(0x29B1), checksumCRC16CCITT(data))
+}
+
+func TestRegisterChecksumAlgorithm(t *testing.T) {
+
+	RegisterChecksumAlgorithm("testlength", func(data []byte) uint64 {
+		return uint64(len(data))
+	})
+
+	result, err := Marshal(testChecksumCustom{Data: "ABCDE"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("ABCDE05"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testChecksumUnknown{Data: "ABCDE"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errUnknownChecksumAlgorithm *ErrorUnknownChecksumAlgorithm
+	assert.Equal(t, true, errors.Is(err, errUnknownChecksumAlgorithm))
+
+	var errs = Validate(testChecksumUnknown{})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, true, errors.Is(errs[0], errUnknownChecksumAlgorithm))
+
+	//-------------------------------------------------------------------------
+
+	// an algorithm registered after the first use of the struct type is found
+	_, err = Marshal(testChecksumLate{Data: "ABC"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errUnknownChecksumAlgorithm))
+
+	RegisterChecksumAlgorithm("testlate", func(data []byte) uint64 {
+		return uint64(len(data))
+	})
+
+	result, err = Marshal(testChecksumLate{Data: "ABC"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("ABC03"), result)
+
+	_, err = Compile[testChecksumLate]()
+	assert.Nil(t, err)
+}
+
+type testChecksumLate struct {
+	Data     string `bin:":3"`
+	Checksum int    `bin:":2,checksum:testlate:0-"`
+}
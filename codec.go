@@ -0,0 +1,655 @@
+package binfile
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A fieldPlan holds the annotations of a struct field processed once, so they don't have to be parsed on every call.
+type fieldPlan struct {
+	name                    string
+	binTag                  string
+	annotationList          []string
+	hasAnnotations          bool
+	absoluteAnnotatedPos    int
+	relativeAnnotatedLength int
+	hasAnnotatedAddress     bool
+	isRootRelative          bool
+	valueKind               reflect.Kind
+	arrayAnnotation         string
+	hasArrayAnnotation      bool
+	isTerminatorType        bool
+	isFillType              bool
+	arrayFixedSize          int
+	isFixedSize             bool
+	arraySizeFieldName      string
+	isDynamicSize           bool
+	arraySizeFieldNo        int
+	isStrictSize            bool
+	separator               string
+	hasSeparator            bool
+	terminator              string
+	hasTerminator           bool
+	defaultValue            reflect.Value
+	hasDefault              bool
+	isDefaultFilled         bool
+	lenPrefixWidth          int
+	isLenPrefixBinary       bool
+	hasLenPrefix            bool
+	constValue              string
+	isConstant              bool
+	conditionFieldNo        int
+	conditionValue          string
+	isConditionNegated      bool
+	hasCondition            bool
+	isOptional              bool
+	overlayFieldNo          int
+	hasOverlay              bool
+	isPrimaryView           bool
+	checksumAlgorithm       string
+	checksumFrom            int
+	checksumTo              int
+	hasChecksum             bool
+	lengthFieldNo           int
+	hasLength               bool
+	boundingFieldNo         int
+	hasBoundingField        bool
+	precision               int
+	signConvention          string
+	isSignForced            bool
+	isPadSpace              bool
+	isTrimmed               bool
+	isBlankZero             bool
+	isExact                 bool
+	roundingMode            string
+	hasRoundingMode         bool
+	scale                   float64
+	offset                  float64
+	isScaled                bool
+	nullPatterns            []string
+	nullFlagFieldNo         int
+	hasNullFlag             bool
+
+	// The error found while processing the annotations. It's returned when the field is reached.
+	err error
+	// The errors found after 'err' in the same annotations. They are only reported by Validate.
+	moreErrs []error
+	// The annotations which are not known. They are ignored on processing, but reported by Validate and NewCodec.
+	unknownAnnotations []string
+}
+
+// A structPlan holds the field plans of a struct type in the order of the fields.
+type structPlan struct {
+	recordType reflect.Type
+	tagKey     string
+	fields     []fieldPlan
+
+	// True if the struct has a field with the 'allowshort' annotation, which makes every field optional.
+	allowShort bool
+	// The terminator of a 'terminator' annotation which isn't on an array. It replaces the terminator
+	// of the call for the struct and the structs nested in it.
+	terminator    string
+	hasTerminator bool
+	// True if the struct has a field with the 'overlay', 'checksum' or 'length' annotation or an array with a size field
+	// filled from the slice, so the positions of the fields have to be tracked.
+	hasOverlays     bool
+	hasChecksums    bool
+	hasLengths      bool
+	hasDerivedSizes bool
+}
+
+// The key of the struct tag holding the annotations, unless another one is chosen for the call.
+const defaultTagKey = "bin"
+
+// The key of the structPlanCache, as a struct type has a plan for every tag key it's used with.
+type structPlanKey struct {
+	recordType reflect.Type
+	tagKey     string
+}
+
+// Caches the *structPlan of every processed struct type keyed by the reflect.Type and the tag key.
+// The plans are compiled under structPlanMutex, so a plan is only compiled once.
+var structPlanCache sync.Map
+var structPlanMutex sync.Mutex
+
+// Returns the cached plan of the provided struct type and tag key or creates it on first use.
+func getStructPlan(recordType reflect.Type, tagKey string) *structPlan {
+
+	var key = structPlanKey{recordType: recordType, tagKey: tagKey}
+	if cached, isCached := structPlanCache.Load(key); isCached {
+		return cached.(*structPlan)
+	}
+
+	structPlanMutex.Lock()
+	defer structPlanMutex.Unlock()
+	if cached, isCached := structPlanCache.Load(key); isCached { // compiled while waiting for the lock
+		return cached.(*structPlan)
+	}
+
+	var plan = compileStructPlan(recordType, tagKey)
+	structPlanCache.Store(key, plan)
+	return plan
+}
+
+// Processes the tags with the provided key of every field in the struct type.
+// A field without such a tag falls back to its 'bin' tag.
+//
+// NOTE: Errors are not returned but stored in the field plans, so they are reported when the field is processed.
+func compileStructPlan(recordType reflect.Type, tagKey string) *structPlan {
+
+	var plan = &structPlan{recordType: recordType, tagKey: tagKey, fields: make([]fieldPlan, recordType.NumField())}
+
+	for fieldNo := 0; fieldNo < recordType.NumField(); fieldNo++ {
+
+		var structField = recordType.Field(fieldNo)
+		var field = &plan.fields[fieldNo]
+
+		field.name = structField.Name
+		var isTagFound bool
+		if field.binTag, isTagFound = structField.Tag.Lookup(tagKey); !isTagFound {
+			field.binTag = structField.Tag.Get(defaultTagKey)
+		}
+		field.valueKind = structField.Type.Kind()
+		field.absoluteAnnotatedPos, field.relativeAnnotatedLength = -1, -1
+		field.precision, field.signConvention, field.scale = -1, signLeading, 1
+
+		var err error
+		if field.annotationList, field.hasAnnotations, err = getAnnotationList(field.binTag); err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, err)
+			continue
+		}
+		field.unknownAnnotations = getUnknownAnnotations(field.annotationList)
+
+		terminator, hasTerminator, err := getTerminatorFromAnnotation(field.annotationList)
+		if err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, err)
+			continue
+		}
+
+		var isMarkerField, isAllowShort = isStructMarkerField(structField), hasAnnotationAllowShort(field.annotationList)
+		if !isMarkerField && (isAllowShort || (hasTerminator && field.valueKind != reflect.Slice)) {
+			field.err = newProcessingFieldError(field.name, field.binTag, ErrorMisplacedStructAnnotation)
+			continue
+		}
+
+		if isMarkerField && (isAllowShort || hasTerminator) {
+			plan.allowShort = plan.allowShort || isAllowShort
+			if hasTerminator {
+				plan.terminator, plan.hasTerminator = terminator, true
+			}
+			continue // a marker for the whole struct, usually a blank (_) field
+		}
+		field.terminator, field.hasTerminator = terminator, hasTerminator
+		field.isOptional = hasAnnotationOptional(field.annotationList)
+
+		if err := compileCondition(recordType, fieldNo, field); err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, err)
+			continue
+		}
+
+		if field.constValue, field.isConstant = getConstantFromAnnotation(field.annotationList); field.isConstant {
+			field.err = compileConstantField(field) // constant fields may be unexported as their value comes from the annotation
+			continue
+		}
+
+		if !structField.IsExported() {
+			if field.binTag != "" {
+				field.err = newProcessingFieldError(field.name, field.binTag, ErrorExportedFieldNotAnnotated)
+			}
+			continue // TODO: this won't notify you about accidentally not exported nested structs
+		}
+
+		field.absoluteAnnotatedPos, field.relativeAnnotatedLength, field.hasAnnotatedAddress, err = getAddressAnnotation(field.annotationList)
+		if err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, newInvalidAddressAnnotationError(err))
+			continue
+		}
+		field.isRootRelative = isRootRelativeAddress(field.annotationList)
+
+		if field.valueKind == reflect.Struct || !field.hasAnnotations {
+			continue // nested structs have their own plan and unannotated fields are not processed
+		}
+
+		if errs := compileValueFormat(recordType, field); len(errs) > 0 {
+			field.err = newProcessingFieldError(field.name, field.binTag, errs[0])
+			for _, err := range errs[1:] {
+				field.moreErrs = append(field.moreErrs, newProcessingFieldError(field.name, field.binTag, err))
+			}
+			continue
+		}
+
+		field.lenPrefixWidth, field.isLenPrefixBinary, field.hasLenPrefix, err = getLengthPrefixFromAnnotation(field.annotationList)
+		if err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, err)
+			continue
+		}
+
+		field.checksumAlgorithm, field.checksumFrom, field.checksumTo, field.hasChecksum, err = getChecksumFromAnnotation(field.annotationList)
+		if err == nil && field.hasChecksum && field.valueKind != reflect.String && field.valueKind != reflect.Int {
+			err = newUnsupportedTypeError(structField.Type)
+		}
+		if err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, err)
+			continue
+		}
+		plan.hasChecksums = plan.hasChecksums || field.hasChecksum
+
+		if err = compileLengthField(recordType, fieldNo, field); err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, err)
+			continue
+		}
+		plan.hasLengths = plan.hasLengths || field.hasLength
+
+		if err = compileOverlay(plan, fieldNo, field); err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, err)
+			continue
+		}
+
+		if err = compileDefault(structField.Type, field); err != nil {
+			field.err = newProcessingFieldError(field.name, field.binTag, err)
+			continue
+		}
+
+		field.separator, field.hasSeparator = getSeparatorFromAnnotation(field.annotationList)
+		if field.hasSeparator && (field.separator == "" || field.valueKind != reflect.Slice || isByteSliceType(structField.Type)) {
+			field.err = newProcessingFieldError(field.name, field.binTag, ErrorInvalidSeparator)
+			continue
+		}
+
+		if field.hasLenPrefix && (field.valueKind == reflect.String || isByteSliceType(structField.Type)) {
+			continue // the length comes from the prefix
+		}
+
+		if field.valueKind == reflect.Slice && field.hasLenPrefix {
+
+			if structField.Type.Elem().Kind() != reflect.Struct && !field.hasAnnotatedAddress {
+				field.err = newProcessingFieldError(field.name, field.binTag, ErrorMissingAddressAnnotation)
+			}
+
+			continue
+		}
+
+		if field.valueKind == reflect.Slice {
+
+			field.arrayAnnotation, field.hasArrayAnnotation = getArrayAnnotation(field.annotationList)
+			if !field.hasArrayAnnotation {
+				field.err = newProcessingFieldError(field.name, field.binTag, ErrorMissingArrayAnnotation)
+				continue
+			}
+
+			if structField.Type.Elem().Kind() != reflect.Struct && !field.hasAnnotatedAddress {
+				field.err = newProcessingFieldError(field.name, field.binTag, ErrorMissingAddressAnnotation)
+				continue
+			}
+
+			field.isTerminatorType = isArrayTypeTerminator(field.arrayAnnotation)
+			field.isFillType = isArrayTypeFill(field.arrayAnnotation)
+			if !field.isTerminatorType && !field.isFillType {
+				if field.arrayFixedSize, field.isFixedSize = getArrayFixedSize(field.arrayAnnotation); !field.isFixedSize {
+					field.arraySizeFieldName, field.isDynamicSize = getArraySizeFieldName(field.arrayAnnotation)
+					field.arraySizeFieldNo, field.isStrictSize = -1, hasAnnotationStrictSize(field.annotationList)
+					if sizeField, isFieldFound := recordType.FieldByName(field.arraySizeFieldName); isFieldFound && len(sizeField.Index) == 1 {
+						field.arraySizeFieldNo = sizeField.Index[0]
+					}
+					plan.hasDerivedSizes = plan.hasDerivedSizes || (field.isDynamicSize && !field.isStrictSize)
+				}
+			}
+
+			continue
+		}
+
+		if !field.hasAnnotatedAddress {
+			field.err = newProcessingFieldError(field.name, field.binTag, ErrorMissingAddressAnnotation)
+		}
+	}
+
+	// a length field which comes before its field bounds it on unmarshaling
+	for fieldNo := range plan.fields {
+		var field = &plan.fields[fieldNo]
+		if field.hasLength && field.lengthFieldNo > fieldNo {
+			plan.fields[field.lengthFieldNo].boundingFieldNo, plan.fields[field.lengthFieldNo].hasBoundingField = fieldNo, true
+		}
+	}
+
+	return plan
+}
+
+// Returns the annotations of the list which are not known, see isKnownAnnotation.
+func getUnknownAnnotations(annotationList []string) []string {
+	var unknownAnnotations []string
+	for _, annotation := range annotationList {
+		if !isKnownAnnotation(annotation) {
+			unknownAnnotations = append(unknownAnnotations, annotation)
+		}
+	}
+	return unknownAnnotations
+}
+
+// Processes the annotations formatting the value of the field: the precision, the sign convention, the rounding mode,
+// the scaling, the null sentinels and the flags. The 'nullflag' annotation must refer to a bool field of the struct.
+//
+// Returns every error found, as the annotations don't depend on each other.
+func compileValueFormat(recordType reflect.Type, field *fieldPlan) []error {
+
+	var errs []error
+	var err error
+	if field.precision, err = getPrecisionFromAnnotation(field.annotationList); err != nil {
+		errs = append(errs, err)
+	}
+	if field.signConvention, err = getSignConventionFromAnnotation(field.annotationList); err != nil {
+		errs = append(errs, err)
+	}
+	if field.roundingMode, field.hasRoundingMode, err = getRoundingModeFromAnnotation(field.annotationList); err != nil {
+		errs = append(errs, err)
+	}
+	if field.scale, field.offset, field.isScaled, err = getScalingFromAnnotation(field.annotationList); err != nil {
+		errs = append(errs, err)
+	}
+
+	field.isSignForced = hasAnnotationForceSign(field.annotationList)
+	field.isPadSpace = hasAnnotationPadspace(field.annotationList)
+	field.isTrimmed = hasAnnotationTrim(field.annotationList)
+	field.isBlankZero = hasAnnotationBlankZero(field.annotationList)
+	field.isExact = hasAnnotationExact(field.annotationList)
+	field.nullPatterns = getNullAnnotations(field.annotationList)
+
+	var flagName string
+	if flagName, field.hasNullFlag = getAnnotationValue(field.annotationList, "nullflag"); !field.hasNullFlag {
+		return errs
+	}
+
+	var flagField, isFieldFound = recordType.FieldByName(flagName)
+	if !isFieldFound || len(flagField.Index) != 1 {
+		return append(errs, ErrorUnknownFieldName)
+	}
+	if flagField.Type.Kind() != reflect.Bool {
+		return append(errs, newUnsupportedTypeError(flagField.Type))
+	}
+	field.nullFlagFieldNo = flagField.Index[0]
+
+	return errs
+}
+
+// Checks if the field can hold the annotations for the whole struct, which is a blank (_) or struct{} field,
+// and returns a bool accordingly.
+func isStructMarkerField(structField reflect.StructField) bool {
+	return structField.Name == "_" || structField.Type == reflect.TypeOf(struct{}{})
+}
+
+// Processes the 'default' annotation of the field. The value is converted to the type of the field
+// (the element type for pointers), which must be a primitive type.
+func compileDefault(fieldType reflect.Type, field *fieldPlan) error {
+
+	var defaultValue, hasDefault = getAnnotationValue(field.annotationList, "default")
+	if !hasDefault {
+		return nil
+	}
+
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if !isSupportedSimpleType(fieldType) {
+		return newUnsupportedTypeError(fieldType)
+	}
+
+	field.defaultValue = reflect.New(fieldType).Elem()
+	switch fieldType.Kind() {
+	case reflect.String:
+		field.defaultValue.SetString(defaultValue)
+	case reflect.Int:
+		var intValue, err = strconv.Atoi(defaultValue)
+		if err != nil {
+			return newInvalidDefaultError(defaultValue)
+		}
+		field.defaultValue.SetInt(int64(intValue))
+	default:
+		var floatValue, err = strconv.ParseFloat(defaultValue, fieldType.Bits())
+		if err != nil {
+			return newInvalidDefaultError(defaultValue)
+		}
+		field.defaultValue.SetFloat(floatValue)
+	}
+
+	field.hasDefault, field.isDefaultFilled = true, hasAnnotationFillDefault(field.annotationList)
+	return nil
+}
+
+// Processes the 'if' annotation of the field. The referenced field must come before it, so its value is already read on unmarshaling.
+func compileCondition(recordType reflect.Type, fieldNo int, field *fieldPlan) error {
+
+	var conditionFieldName string
+	var err error
+	conditionFieldName, field.conditionValue, field.isConditionNegated, field.hasCondition, err = getConditionFromAnnotation(field.annotationList)
+	if err != nil || !field.hasCondition {
+		return err
+	}
+
+	var conditionField, isFieldFound = recordType.FieldByName(conditionFieldName)
+	if !isFieldFound || len(conditionField.Index) != 1 {
+		return ErrorUnknownFieldName
+	}
+	if conditionField.Index[0] >= fieldNo {
+		return ErrorConditionFieldNotBefore
+	}
+	field.conditionFieldNo = conditionField.Index[0]
+
+	return nil
+}
+
+// Processes the 'length' annotation of the field. It holds the length of the struct with "self" or of the field with the
+// provided name. The 'lengthFieldNo' is -1 for the struct.
+func compileLengthField(recordType reflect.Type, fieldNo int, field *fieldPlan) error {
+
+	var lengthOf string
+	if lengthOf, field.hasLength = getAnnotationValue(field.annotationList, "length"); !field.hasLength {
+		return nil
+	}
+
+	if field.valueKind != reflect.Int {
+		return newUnsupportedTypeError(recordType.Field(fieldNo).Type)
+	}
+
+	field.lengthFieldNo = -1
+	if lengthOf != lengthSelf {
+		var lengthField, isFieldFound = recordType.FieldByName(lengthOf)
+		if !isFieldFound || len(lengthField.Index) != 1 {
+			return ErrorUnknownFieldName
+		}
+		field.lengthFieldNo = lengthField.Index[0]
+	}
+
+	return nil
+}
+
+// Processes the 'overlay' annotation of the field. The overlaid field must be an earlier primitive field, which isn't a view
+// itself and is at least as long as the view. The view has no absolute position as it starts where the overlaid field starts.
+// A view which isn't primary needs a condition, as it would be read from bytes in another format otherwise.
+func compileOverlay(plan *structPlan, fieldNo int, field *fieldPlan) error {
+
+	var overlaidFieldName string
+	overlaidFieldName, field.hasOverlay = getAnnotationValue(field.annotationList, "overlay")
+	if !field.hasOverlay {
+		return nil
+	}
+	field.isPrimaryView = hasAnnotationPrimary(field.annotationList)
+
+	var overlaidFieldNo, isFieldFound = plan.fieldNo(overlaidFieldName)
+	if !isFieldFound || overlaidFieldNo >= fieldNo {
+		return ErrorUnknownFieldName
+	}
+
+	var overlaidField = &plan.fields[overlaidFieldNo]
+	if overlaidField.err != nil || overlaidField.hasOverlay || overlaidField.isConstant || overlaidField.hasLenPrefix ||
+		overlaidField.valueKind == reflect.Struct || overlaidField.valueKind == reflect.Slice || !overlaidField.hasAnnotatedAddress ||
+		field.valueKind == reflect.Slice || field.hasLenPrefix || field.absoluteAnnotatedPos != -1 ||
+		field.relativeAnnotatedLength > overlaidField.relativeAnnotatedLength {
+		return ErrorInvalidOverlay
+	}
+	if !field.isPrimaryView && !field.hasCondition {
+		return ErrorOverlayWithoutCondition
+	}
+
+	field.overlayFieldNo = overlaidFieldNo
+	plan.hasOverlays = true
+
+	return nil
+}
+
+// Processes the address of a field with a 'const' annotation. The length defaults to the length of the value,
+// a longer annotated length pads the value with spaces.
+func compileConstantField(field *fieldPlan) error {
+
+	var err error
+	field.absoluteAnnotatedPos, field.relativeAnnotatedLength, field.hasAnnotatedAddress, err = getAddressAnnotation(field.annotationList)
+	if err != nil {
+		return newProcessingFieldError(field.name, field.binTag, newInvalidAddressAnnotationError(err))
+	}
+	field.isRootRelative = isRootRelativeAddress(field.annotationList)
+
+	if !field.hasAnnotatedAddress || field.relativeAnnotatedLength == -1 {
+		field.relativeAnnotatedLength = len(field.constValue)
+	}
+	if field.relativeAnnotatedLength < len(field.constValue) {
+		return newProcessingFieldError(field.name, field.binTag, newInvalidValueLengthError(field.constValue, field.relativeAnnotatedLength))
+	}
+
+	field.constValue += strings.Repeat(" ", field.relativeAnnotatedLength-len(field.constValue))
+
+	return nil
+}
+
+// Checks if the positions of the fields have to be tracked while processing the struct and returns a bool accordingly.
+func (plan *structPlan) tracksFieldPositions() bool {
+	return plan.hasOverlays || plan.hasChecksums || plan.hasLengths || plan.hasDerivedSizes
+}
+
+// Returns the size of a fixed size array with elements of the provided size, including the separators between them.
+func (field *fieldPlan) fixedArraySize(elemSize int) int {
+	if field.arrayFixedSize == 0 {
+		return 0
+	}
+	return field.arrayFixedSize*elemSize + (field.arrayFixedSize-1)*len(field.separator)
+}
+
+// Returns the number of the field with the provided 'name' and a bool accordingly. (', ok' idiom)
+func (plan *structPlan) fieldNo(name string) (int, bool) {
+	for fieldNo := range plan.fields {
+		if plan.fields[fieldNo].name == name {
+			return fieldNo, true
+		}
+	}
+	return -1, false
+}
+
+// Returns the first error found in the plan or in the plans of the nested structs.
+// Unknown annotations and checksum algorithms are only an error if 'isUnknownReported' is true, as the annotations
+// are ignored on processing and the algorithms are looked up when used, so they can be registered later.
+func (plan *structPlan) firstError(isUnknownReported bool) error {
+	return plan.firstErrorOf(isUnknownReported, map[reflect.Type]bool{})
+}
+
+// Works like firstError, but skips the struct types in 'visited', so a recursive type is only checked once.
+func (plan *structPlan) firstErrorOf(isUnknownReported bool, visited map[reflect.Type]bool) error {
+
+	visited[plan.recordType] = true
+
+	for fieldNo := range plan.fields {
+
+		var field = &plan.fields[fieldNo]
+		if isUnknownReported && len(field.unknownAnnotations) > 0 {
+			return newProcessingFieldError(field.name, field.binTag, newUnknownAnnotationError(field.unknownAnnotations[0]))
+		}
+		if field.err != nil {
+			return field.err
+		}
+		if _, isRegistered := getChecksumAlgorithm(field.checksumAlgorithm); isUnknownReported && field.hasChecksum && !isRegistered {
+			return newProcessingFieldError(field.name, field.binTag, newUnknownChecksumAlgorithmError(field.checksumAlgorithm))
+		}
+
+		var fieldType = plan.recordType.Field(fieldNo).Type
+		if field.valueKind == reflect.Slice && field.hasAnnotations {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && plan.recordType.Field(fieldNo).IsExported() && !field.isConstant && !visited[fieldType] {
+			if err := getStructPlan(fieldType, plan.tagKey).firstErrorOf(isUnknownReported, visited); err != nil {
+				return newProcessingFieldError(field.name, field.binTag, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// A Codec converts a specific annotated struct type. The annotations are processed and validated once on creation.
+//
+// The package-level Marshal and Unmarshal functions share the same processed annotations, so a Codec
+// is mostly useful for finding annotation errors early. It's safe for concurrent use.
+type Codec struct {
+	recordType reflect.Type
+	tagKey     string
+}
+
+// Processes the annotations of the provided struct type (or pointer to it) and returns a Codec for it
+// or an error if an annotation is invalid.
+func NewCodec(recordType reflect.Type) (*Codec, error) {
+	return NewCodecWithTagKey(recordType, defaultTagKey)
+}
+
+// Works like NewCodec, but the annotations are read from the tags with the provided key, e.g. "bin_v2".
+// See MarshalWithTagKey.
+func NewCodecWithTagKey(recordType reflect.Type, tagKey string) (*Codec, error) {
+
+	for recordType.Kind() == reflect.Ptr {
+		recordType = recordType.Elem()
+	}
+
+	if recordType.Kind() != reflect.Struct {
+		return nil, newUnsupportedTypeError(recordType)
+	}
+
+	if err := getStructPlan(recordType, tagKey).firstError(true); err != nil {
+		return nil, err
+	}
+
+	return &Codec{recordType: recordType, tagKey: tagKey}, nil
+}
+
+// Returns a Codec for the struct type 'T'. See NewCodec.
+func Compile[T any]() (*Codec, error) {
+	return NewCodec(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// Works like the package-level Marshal, but only accepts the struct type of the codec or a slice of it.
+func (c *Codec) Marshal(target interface{}, padding byte, enc Encoding, tz Timezone, arrayTerminator string) ([]byte, error) {
+
+	if !c.acceptsType(reflect.TypeOf(target)) {
+		return []byte{}, newUnsupportedTypeError(reflect.TypeOf(target))
+	}
+
+	return MarshalWithTagKey(target, c.tagKey, padding, enc, tz, arrayTerminator)
+}
+
+// Works like the package-level Unmarshal, but only accepts a pointer to the struct type of the codec or to a slice of it.
+func (c *Codec) Unmarshal(inputBytes []byte, target interface{}, enc Encoding, tz Timezone, arrayTerminator string) (int, error) {
+
+	if reflect.TypeOf(target).Kind() != reflect.Ptr || !c.acceptsType(reflect.TypeOf(target).Elem()) {
+		return 0, newUnsupportedTypeError(reflect.TypeOf(target))
+	}
+
+	return UnmarshalWithTagKey(inputBytes, target, c.tagKey, enc, tz, arrayTerminator)
+}
+
+// Checks if the provided type is the struct type of the codec or a slice of it (or pointer to them).
+func (c *Codec) acceptsType(targetType reflect.Type) bool {
+
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+	if targetType.Kind() == reflect.Slice {
+		targetType = targetType.Elem()
+	}
+
+	return targetType == c.recordType
+}
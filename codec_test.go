@@ -0,0 +1,202 @@
+package binfile
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+//-Codec-----------------------------------------------------------------------
+
+type testCodecRecord struct {
+	RecordType string `bin:":2"`
+	Value      int    `bin:":3"`
+	Nested     struct {
+		Inner []int `bin:"array:2,:1"`
+	}
+}
+
+type testCodecInvalidNested struct {
+	Valid  string `bin:":2"`
+	Nested struct {
+		MissingArray []int `bin:":1"`
+	}
+}
+
+type testCodecInvalidAddress struct {
+	Invalid string `bin:"99999999999999999999:2"` // overflows the integer
+}
+
+type testCodecInvalidSign struct {
+	Value int `bin:":3,sign:bogus"`
+}
+
+type testCodecInvalidRounding struct {
+	Value float32 `bin:":5,round:weird"`
+}
+
+type testCodecInvalidScale struct {
+	Value int `bin:":3,scale:0"`
+}
+
+type testCodecInvalidPrecision struct {
+	Value float32 `bin:":5,precision:x"`
+}
+
+type testCodecInvalidChecksum struct {
+	Value    string `bin:":3"`
+	Checksum string `bin:":2,checksum:sum99:0-"`
+}
+
+type testCodecMisspelled struct {
+	Value string `bin:":3,trimm"`
+}
+
+type testCodecInvalidNullFlag struct {
+	Value  int `bin:":3,null:---,nullflag:IsNull"`
+	IsNull int
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+
+	codec, err := NewCodec(reflect.TypeOf(testCodecRecord{}))
+	assert.Nil(t, err)
+
+	var inputData testCodecRecord
+	inputData.RecordType = "D "
+	inputData.Value = 42
+	inputData.Nested.Inner = []int{1, 2}
+
+	result, err := codec.Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("D 04212"), result)
+
+	var output testCodecRecord
+	position, err := codec.Unmarshal(result, &output, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, len(result), position)
+	assert.Equal(t, inputData, output)
+
+	//-------------------------------------------------------------------------
+
+	var wrongType testStringMarshal
+	_, err = codec.Marshal(wrongType, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errUnsupportedType *ErrorUnsupportedType
+	assert.Equal(t, true, errors.Is(err, errUnsupportedType))
+}
+
+func TestCodecReportsAnnotationErrors(t *testing.T) {
+
+	_, err := Compile[testCodecInvalidNested]()
+	assert.Equal(t, true, errors.Is(err, ErrorMissingArrayAnnotation))
+
+	_, err = NewCodec(reflect.TypeOf(&testCodecInvalidAddress{}))
+	var errInvalidAddress *ErrorInvalidAddressAnnotation
+	assert.Equal(t, true, errors.Is(err, errInvalidAddress))
+
+	_, err = NewCodec(reflect.TypeOf(42))
+	var errUnsupportedType *ErrorUnsupportedType
+	assert.Equal(t, true, errors.Is(err, errUnsupportedType))
+
+	//-------------------------------------------------------------------------
+
+	// the values of the annotations are checked before any data is processed
+	_, err = Compile[testCodecInvalidSign]()
+	var errInvalidSign *ErrorInvalidSignConvention
+	assert.Equal(t, true, errors.Is(err, errInvalidSign))
+
+	_, err = Compile[testCodecInvalidRounding]()
+	var errInvalidRounding *ErrorInvalidRoundingMode
+	assert.Equal(t, true, errors.Is(err, errInvalidRounding))
+
+	_, err = Compile[testCodecInvalidScale]()
+	var errInvalidScaling *ErrorInvalidScaling
+	assert.Equal(t, true, errors.Is(err, errInvalidScaling))
+
+	_, err = Compile[testCodecInvalidPrecision]()
+	var errInvalidPrecision *ErrorInvalidPrecision
+	assert.Equal(t, true, errors.Is(err, errInvalidPrecision))
+
+	_, err = Compile[testCodecInvalidChecksum]()
+	var errUnknownChecksum *ErrorUnknownChecksumAlgorithm
+	assert.Equal(t, true, errors.Is(err, errUnknownChecksum))
+
+	_, err = Compile[testCodecMisspelled]()
+	var errUnknownAnnotation *ErrorUnknownAnnotation
+	assert.Equal(t, true, errors.Is(err, errUnknownAnnotation))
+
+	// without a codec an unknown annotation is ignored as before
+	var misspelled testCodecMisspelled
+	_, err = Unmarshal([]byte("AB "), &misspelled, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, "AB ", misspelled.Value)
+
+	_, err = Compile[testCodecInvalidNullFlag]()
+	assert.Equal(t, true, errors.Is(err, errUnsupportedType))
+}
+
+type testCodecRecursive struct {
+	V    string               `bin:":1"`
+	Kids []testCodecRecursive `bin:"array:terminator"`
+}
+
+func TestCodecRecursiveType(t *testing.T) {
+
+	// the annotations of a type containing itself are checked once
+	_, err := Compile[testCodecRecursive]()
+	assert.Nil(t, err)
+
+	var inputData = testCodecRecursive{V: "A", Kids: []testCodecRecursive{{V: "B"}}}
+	result, err := Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("AB\r\r"), result)
+}
+
+func TestCodecConcurrentUse(t *testing.T) {
+
+	codec, err := Compile[testCodecRecord]()
+	assert.Nil(t, err)
+
+	var waitGroup sync.WaitGroup
+	var results = make([][]byte, 16)
+	for i := range results {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			var inputData testCodecRecord
+			inputData.RecordType = "D "
+			inputData.Value = i
+			results[i], _ = codec.Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+		}(i)
+	}
+	waitGroup.Wait()
+
+	for i := range results {
+		var output testCodecRecord
+		_, err = codec.Unmarshal(results[i], &output, EncodingUTF8, TimezoneUTC, "\r")
+		assert.Nil(t, err)
+		assert.Equal(t, i, output.Value)
+	}
+}
+
+func TestCodecWithTagKey(t *testing.T) {
+
+	codec, err := NewCodecWithTagKey(reflect.TypeOf(testTagKeyMarshal{}), "bin_v2")
+	assert.Nil(t, err)
+
+	var inputData = testTagKeyMarshal{RecordType: "D", SampleId: "S1", Value: 42, Flag: "H"}
+	inputData.Nested.Code = "AB"
+
+	result, err := codec.Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("D    S10042H AB"), result)
+
+	var output testTagKeyMarshal
+	_, err = codec.Unmarshal(result, &output, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, "H", output.Flag)
+}
@@ -1,34 +1,71 @@
 package binfile
 
 import (
+	"bytes"
+	"fmt"
 	"reflect"
+	"strings"
 )
 
 // Searches for a field in 'structValue' with the provided 'name' and returns the valid integer value from it or an error.
 func resolveDynamicArraySize(structValue reflect.Value, name string) (int, error) {
 
-	var arraySize = -1
+	var fieldVal, err = resolveDynamicArraySizeField(structValue, name)
+	if err != nil {
+		return -1, err
+	}
 
-	// TODO: this will only work if referenced field is already processed but won't give error otherwise
-	if fieldVal, isFieldFound := getFieldFromStruct(structValue, name); isFieldFound {
-		var fieldKind = reflect.TypeOf(fieldVal.Interface()).Kind()
-		if fieldKind != reflect.Int {
-			return arraySize, newUnsupportedTypeError(reflect.TypeOf(fieldVal.Interface()))
-		}
-		arraySize = int(fieldVal.Int())
-		if int64(arraySize) != fieldVal.Int() {
-			return arraySize, ErrorIntConversionOverflow
-		}
-		if arraySize < 0 {
-			return arraySize, newInvalidSizeForArrayError(arraySize)
-		}
-	} else {
-		return arraySize, ErrorUnknownFieldName
+	var arraySize = int(fieldVal.Int())
+	if int64(arraySize) != fieldVal.Int() {
+		return arraySize, ErrorIntConversionOverflow
+	}
+	if arraySize < 0 {
+		return arraySize, newInvalidSizeForArrayError(arraySize)
 	}
 
 	return arraySize, nil
 }
 
+// Searches for a field in 'structValue' with the provided 'name' and returns it if it can hold an array size or an error.
+func resolveDynamicArraySizeField(structValue reflect.Value, name string) (reflect.Value, error) {
+
+	var fieldVal, isFieldFound = getFieldFromStruct(structValue, name)
+	if !isFieldFound {
+		return reflect.Value{}, ErrorUnknownFieldName
+	}
+	if fieldVal.Kind() != reflect.Int {
+		return reflect.Value{}, newUnsupportedTypeError(fieldVal.Type())
+	}
+
+	return fieldVal, nil
+}
+
+// Checks if the condition of the 'if' annotation of the field is met by the value of the referenced field in the struct
+// and returns a bool accordingly. The value is compared in its default format without surrounding spaces. A nil pointer is blank.
+func isConditionMet(structValue reflect.Value, field *fieldPlan) bool {
+
+	var conditionValue = reflect.Indirect(structValue.Field(field.conditionFieldNo))
+
+	var strvalue = ""
+	if conditionValue.IsValid() {
+		strvalue = strings.TrimSpace(fmt.Sprint(conditionValue))
+	}
+
+	return (strvalue == field.conditionValue) != field.isConditionNegated
+}
+
+// Checks if the read bytes are one of the null sentinels of the field and returns a bool accordingly.
+// Surrounding spaces are ignored.
+func isNullSentinel(byteArray []byte, field *fieldPlan) bool {
+	var strvalue = strings.TrimSpace(string(byteArray))
+	for _, pattern := range field.nullPatterns {
+		if strvalue == strings.TrimSpace(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
 // find a searchstring within an array of strings. only matches full
 // returns
 //   - true if the string is present
@@ -57,6 +94,20 @@ func advanceThroughTerminator(byteArray []byte, currentPos int, arrayTerminator
 	return currentPos, false
 }
 
+// Finds the end of the record which contains 'currentPos'. It's either the position of the next 'arrayTerminator'
+// or the end of the byte array.
+func findRecordEnd(byteArray []byte, currentPos int, arrayTerminator string) int {
+	if currentPos >= len(byteArray) {
+		return len(byteArray)
+	}
+	if arrayTerminator != "" {
+		if idx := bytes.Index(byteArray[currentPos:], []byte(arrayTerminator)); idx >= 0 {
+			return currentPos + idx
+		}
+	}
+	return len(byteArray)
+}
+
 // Creates and adds the padding bytes of provided 'byteToUse' and of requested 'length' to the 'original' byte array.
 // Returns the padded byte array and it's new size.
 func appendPaddingBytes(original []byte, length int, byteToUse byte) ([]byte, int) {
@@ -67,3 +118,44 @@ func appendPaddingBytes(original []byte, length int, byteToUse byte) ([]byte, in
 	var temp = append(original, paddingBytes...)
 	return temp, len(temp)
 }
+
+// Checks if every byte in 'byteArray' is a space character and returns a bool accordingly.
+//
+// NOTE: An empty byte array is not considered blank.
+func isBlankBytes(byteArray []byte) bool {
+	if len(byteArray) == 0 {
+		return false
+	}
+	for _, val := range byteArray {
+		if val != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks if the provided kind is one of the supported numeric kinds and returns a bool accordingly.
+func isNumericKind(kind reflect.Kind) bool {
+	return kind == reflect.Int || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// Returns the slice used for tracking the start or end of the fields of a struct. Every field is at -1 as not processed.
+func newFieldPositions(numFields int) []int {
+	var fieldPositions = make([]int, numFields)
+	for i := range fieldPositions {
+		fieldPositions[i] = -1
+	}
+	return fieldPositions
+}
+
+// Returns the length the length field refers to: the length of the struct from 'initialStartByte' to 'endByte'
+// or of the referenced field (0 if it wasn't processed).
+func measureLength(field *fieldPlan, initialStartByte int, endByte int, fieldStartBytes []int, fieldEndBytes []int) int {
+	if field.lengthFieldNo == -1 {
+		return endByte - initialStartByte
+	}
+	if fieldStartBytes[field.lengthFieldNo] == -1 {
+		return 0
+	}
+	return fieldEndBytes[field.lengthFieldNo] - fieldStartBytes[field.lengthFieldNo]
+}
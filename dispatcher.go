@@ -0,0 +1,182 @@
+package binfile
+
+import (
+	"reflect"
+)
+
+// A Dispatcher decodes a stream of different kinds of messages separated by a terminator. The struct type of
+// every message is chosen by its leading code or by the value of a discriminator field.
+//
+// The registration methods can be chained. An invalid registration is reported by Unmarshal.
+type Dispatcher struct {
+	entries            []dispatchEntry
+	discriminatorField string
+	tagKey             string
+	err                error
+}
+
+// A dispatchEntry holds a registered code along with the codec of its struct type and an optional handler.
+// The code is at 'codePosition' in the messages, see getCodePosition.
+type dispatchEntry struct {
+	code         string
+	codePosition int
+	codec        *Codec
+	handler      func(record interface{}) error
+}
+
+// Returns an empty Dispatcher which matches the registered codes at the start of the messages.
+func NewDispatcher() *Dispatcher {
+	return NewDispatcherWithTagKey(defaultTagKey)
+}
+
+// Works like NewDispatcher, but the annotations of the registered struct types are read from the tags
+// with the provided key, e.g. "bin_v2". See MarshalWithTagKey.
+func NewDispatcherWithTagKey(tagKey string) *Dispatcher {
+	return &Dispatcher{tagKey: tagKey}
+}
+
+// Registers the struct type of 'record' (or pointer to it) for the messages with the provided 'code'.
+// The decoded messages are returned by Unmarshal.
+func (d *Dispatcher) Register(code string, record interface{}) *Dispatcher {
+	return d.RegisterHandler(code, record, nil)
+}
+
+// Registers the struct type of 'record' (or pointer to it) for the messages with the provided 'code'.
+// The decoded messages are passed to the 'handler' instead of being returned by Unmarshal.
+// The code must not be empty, as it would match every message.
+func (d *Dispatcher) RegisterHandler(code string, record interface{}, handler func(record interface{}) error) *Dispatcher {
+
+	if d.err != nil {
+		return d
+	}
+
+	if code == "" {
+		d.err = ErrorEmptyRecordCode
+		return d
+	}
+
+	if reflect.TypeOf(record) == nil {
+		d.err = newUnsupportedTypeError(reflect.TypeOf(record))
+		return d
+	}
+
+	var codec, err = NewCodecWithTagKey(reflect.TypeOf(record), d.tagKey)
+	if err != nil {
+		d.err = err
+		return d
+	}
+
+	var entry = dispatchEntry{code: code, codec: codec, handler: handler}
+	if entry.codePosition, err = d.getCodePosition(codec); err != nil {
+		d.err = err
+		return d
+	}
+
+	d.entries = append(d.entries, entry)
+	return d
+}
+
+// Matches the registered codes against the value of the field with the provided name instead of the start of the
+// messages. The field must be in every registered struct type at a position which doesn't depend on the data.
+func (d *Dispatcher) DiscriminateByField(fieldName string) *Dispatcher {
+
+	d.discriminatorField = fieldName
+
+	// the positions are computed once, so the entries registered before are updated
+	for entryNo := range d.entries {
+		if d.err != nil {
+			break
+		}
+		d.entries[entryNo].codePosition, d.err = d.getCodePosition(d.entries[entryNo].codec)
+	}
+
+	return d
+}
+
+// Decodes the messages in the byte array, which need to be separated by the 'arrayTerminator'.
+// The first registered code that matches a message chooses its struct type.
+//
+// Returns the decoded messages that have no handler in order, the position after the last message and an error
+// if a message couldn't be decoded or matched.
+func (d *Dispatcher) Unmarshal(inputBytes []byte, enc Encoding, tz Timezone, arrayTerminator string) ([]interface{}, int, error) {
+
+	if d.err != nil {
+		return nil, 0, d.err
+	}
+
+	var records = []interface{}{}
+	var currentByte = 0
+	for currentByte < len(inputBytes) {
+
+		// the code has to be in the message, not in the ones after it
+		var recordEnd = findRecordEnd(inputBytes, currentByte, arrayTerminator)
+		var entryNo, isMatched = d.matchEntry(inputBytes[currentByte:recordEnd])
+		if !isMatched {
+			return records, currentByte, newUnknownRecordTypeError(string(inputBytes[currentByte:recordEnd]))
+		}
+		var entry = &d.entries[entryNo]
+
+		var outputTarget = reflect.New(entry.codec.recordType)
+		var processedBytes, err = entry.codec.Unmarshal(inputBytes[currentByte:], outputTarget.Interface(), enc, tz, arrayTerminator)
+		if err != nil {
+			return records, currentByte + processedBytes, err
+		}
+		if processedBytes == 0 { // the same message would be decoded again and again
+			return records, currentByte, ErrorNoBytesRead
+		}
+		currentByte += processedBytes
+
+		if entry.handler != nil {
+			if err = entry.handler(outputTarget.Elem().Interface()); err != nil {
+				return records, currentByte, err
+			}
+		} else {
+			records = append(records, outputTarget.Elem().Interface())
+		}
+
+		// messages are always separated by a terminator - advance through
+		currentByte, _ = advanceThroughTerminator(inputBytes, currentByte, arrayTerminator)
+	}
+
+	return records, currentByte, nil
+}
+
+// Returns the position of the code in the messages of the codec's struct type. It's the start of the message
+// or the static offset of the discriminator field.
+func (d *Dispatcher) getCodePosition(codec *Codec) (int, error) {
+
+	if d.discriminatorField == "" {
+		return 0, nil
+	}
+
+	var layouts, err = LayoutWithTagKey(codec.recordType, d.tagKey)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, layout := range layouts {
+		if layout.Name != d.discriminatorField {
+			continue
+		}
+		if layout.Offset == -1 {
+			return 0, newProcessingFieldError(d.discriminatorField, "", ErrorUnknownFieldPosition)
+		}
+		return layout.Offset, nil
+	}
+
+	return 0, newProcessingFieldError(d.discriminatorField, "", ErrorUnknownFieldName)
+}
+
+// Finds the first entry whose code is at its position in the message and returns its number
+// along with a bool which is true if found. (', ok' idiom)
+func (d *Dispatcher) matchEntry(messageBytes []byte) (int, bool) {
+
+	for entryNo := range d.entries {
+		var codeStart, codeEnd = d.entries[entryNo].codePosition, d.entries[entryNo].codePosition + len(d.entries[entryNo].code)
+		if codeEnd <= len(messageBytes) && string(messageBytes[codeStart:codeEnd]) == d.entries[entryNo].code {
+			return entryNo, true
+		}
+	}
+
+	return -1, false
+}
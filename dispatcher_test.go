@@ -0,0 +1,158 @@
+package binfile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+//-Dispatcher------------------------------------------------------------------
+
+type testDispatchHeader struct {
+	RecordType string `bin:"const:H"`
+	Sender     string `bin:":4,trim"`
+}
+
+type testDispatchData struct {
+	RecordType string `bin:"const:D,:2"`
+	Value      int    `bin:":3"`
+}
+
+type testDispatchTrailer struct {
+	RecordType string `bin:":1"`
+	Count      int    `bin:":2"`
+}
+
+type testDispatchDiscriminated struct {
+	SequenceNo int    `bin:":2"`
+	RecordType string `bin:":1"`
+	Value      int    `bin:":3"`
+}
+
+func TestDispatcherUnmarshal(t *testing.T) {
+
+	var dispatcher = NewDispatcher().
+		Register("H", testDispatchHeader{}).
+		Register("D ", testDispatchData{}).
+		Register("L", testDispatchTrailer{})
+
+	var inputData = []byte("HLAB \rD 042\rD 007\rL02\r")
+
+	records, position, err := dispatcher.Unmarshal(inputData, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputData), position)
+
+	assert.Equal(t, []interface{}{
+		testDispatchHeader{RecordType: "H", Sender: "LAB"},
+		testDispatchData{RecordType: "D ", Value: 42},
+		testDispatchData{RecordType: "D ", Value: 7},
+		testDispatchTrailer{RecordType: "L", Count: 2},
+	}, records)
+
+	//-------------------------------------------------------------------------
+
+	_, position, err = dispatcher.Unmarshal([]byte("HLAB \rX 042\r"), EncodingUTF8, TimezoneUTC, "\r")
+
+	var errUnknownRecordType *ErrorUnknownRecordType
+	assert.Equal(t, true, errors.As(err, &errUnknownRecordType))
+	assert.Equal(t, "X 042", errUnknownRecordType.Record)
+	assert.Equal(t, 6, position)
+
+	//-------------------------------------------------------------------------
+
+	// an empty code would match every message
+	_, _, err = NewDispatcher().Register("", testDispatchTrailer{}).Unmarshal([]byte("L02\r"), EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorEmptyRecordCode))
+
+	// a message of which no byte is read would be decoded again and again
+	_, position, err = NewDispatcher().Register("L", testDispatchEmpty{}).Unmarshal([]byte("L02\r"), EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorNoBytesRead))
+	assert.Equal(t, 0, position)
+}
+
+type testDispatchEmpty struct {
+	Comment string
+}
+
+func TestDispatcherHandlers(t *testing.T) {
+
+	var values []int
+	var dispatcher = NewDispatcher().
+		Register("H", testDispatchHeader{}).
+		RegisterHandler("D ", testDispatchData{}, func(record interface{}) error {
+			values = append(values, record.(testDispatchData).Value)
+			return nil
+		})
+
+	records, _, err := dispatcher.Unmarshal([]byte("HLAB \rD 042\rD 007\r"), EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, []int{42, 7}, values)
+
+	//-------------------------------------------------------------------------
+
+	var errHandler = errors.New("handler failed")
+	dispatcher = NewDispatcher().
+		RegisterHandler("D ", testDispatchData{}, func(record interface{}) error {
+			return errHandler
+		})
+
+	_, _, err = dispatcher.Unmarshal([]byte("D 042\r"), EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, errHandler, err)
+}
+
+func TestDispatcherDiscriminateByField(t *testing.T) {
+
+	var dispatcher = NewDispatcher().
+		Register("D", testDispatchDiscriminated{}).
+		Register("L", testDispatchTrailer{}).
+		DiscriminateByField("RecordType")
+
+	records, _, err := dispatcher.Unmarshal([]byte("01D042\rL01\r"), EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []interface{}{
+		testDispatchDiscriminated{SequenceNo: 1, RecordType: "D", Value: 42},
+		testDispatchTrailer{RecordType: "L", Count: 1},
+	}, records)
+
+	//-------------------------------------------------------------------------
+
+	// the code is only matched inside the message, a short message doesn't match the code of the next one
+	_, position, err := dispatcher.Unmarshal([]byte("0\rD042\r"), EncodingUTF8, TimezoneUTC, "\r")
+	var errUnknownRecordType *ErrorUnknownRecordType
+	assert.Equal(t, true, errors.Is(err, errUnknownRecordType))
+	assert.Equal(t, 0, position)
+
+	//-------------------------------------------------------------------------
+
+	_, _, err = NewDispatcher().
+		Register("D", testDispatchDiscriminated{}).
+		DiscriminateByField("Missing").
+		Unmarshal([]byte("01D042\r"), EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorUnknownFieldName))
+
+	//-------------------------------------------------------------------------
+
+	_, _, err = NewDispatcher().
+		Register("D", []int{}).
+		Unmarshal([]byte("01D042\r"), EncodingUTF8, TimezoneUTC, "\r")
+	var errUnsupportedType *ErrorUnsupportedType
+	assert.Equal(t, true, errors.Is(err, errUnsupportedType))
+}
+
+func TestDispatcherWithTagKey(t *testing.T) {
+
+	var dispatcher = NewDispatcherWithTagKey("bin_v2").
+		Register("D", testTagKeyMarshal{}).
+		DiscriminateByField("RecordType")
+
+	records, _, err := dispatcher.Unmarshal([]byte("D    S10042H AB\r"), EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "H", records[0].(testTagKeyMarshal).Flag)
+	assert.Equal(t, 42, records[0].(testTagKeyMarshal).Value)
+}
@@ -51,6 +51,199 @@ func newInvalidPrecisionError(precision string) error {
 	return &ErrorInvalidPrecision{Precision: precision}
 }
 
+// An ErrorInvalidRoundingMode is returned when the 'round' annotation has an unknown value.
+type ErrorInvalidRoundingMode struct {
+	RoundingMode string
+}
+
+func (e *ErrorInvalidRoundingMode) Error() string {
+	return fmt.Sprintf("invalid rounding mode given '%s'", e.RoundingMode)
+}
+
+func (e *ErrorInvalidRoundingMode) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidRoundingMode)
+	return ok
+}
+
+func newInvalidRoundingModeError(roundingMode string) error {
+	return &ErrorInvalidRoundingMode{RoundingMode: roundingMode}
+}
+
+// An ErrorPrecisionLoss is returned when a float annotated as 'exact' can't be written
+// with the annotated precision without losing digits.
+type ErrorPrecisionLoss struct {
+	Value     string
+	Precision int
+}
+
+func (e *ErrorPrecisionLoss) Error() string {
+	return fmt.Sprintf("value '%s' can't be represented with precision '%d' without losing digits", e.Value, e.Precision)
+}
+
+func (e *ErrorPrecisionLoss) Is(target error) bool {
+	_, ok := target.(*ErrorPrecisionLoss)
+	return ok
+}
+
+func newPrecisionLossError(value string, precision int) error {
+	return &ErrorPrecisionLoss{Value: value, Precision: precision}
+}
+
+// An ErrorInvalidScaling is returned when the value of a 'scale' or 'offset' annotation
+// is an invalid float or the scale is zero.
+type ErrorInvalidScaling struct {
+	Annotation string
+	Value      string
+}
+
+func (e *ErrorInvalidScaling) Error() string {
+	return fmt.Sprintf("invalid %s given '%s'", e.Annotation, e.Value)
+}
+
+func (e *ErrorInvalidScaling) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidScaling)
+	return ok
+}
+
+func newInvalidScalingError(annotation string, value string) error {
+	return &ErrorInvalidScaling{Annotation: annotation, Value: value}
+}
+
+// An ErrorScaledValueNotInteger is returned when scaling an integer field's value doesn't result in an integer.
+type ErrorScaledValueNotInteger struct {
+	Value float64
+}
+
+func (e *ErrorScaledValueNotInteger) Error() string {
+	return fmt.Sprintf("scaled value '%g' is not an integer", e.Value)
+}
+
+func (e *ErrorScaledValueNotInteger) Is(target error) bool {
+	_, ok := target.(*ErrorScaledValueNotInteger)
+	return ok
+}
+
+func newScaledValueNotIntegerError(value float64) error {
+	return &ErrorScaledValueNotInteger{Value: value}
+}
+
+// An ErrorInvalidLengthPrefix is returned when the 'lenprefix' annotation has an invalid width or encoding.
+type ErrorInvalidLengthPrefix struct {
+	LengthPrefix string
+}
+
+func (e *ErrorInvalidLengthPrefix) Error() string {
+	return fmt.Sprintf("invalid length prefix given '%s'", e.LengthPrefix)
+}
+
+func (e *ErrorInvalidLengthPrefix) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidLengthPrefix)
+	return ok
+}
+
+func newInvalidLengthPrefixError(lengthPrefix string) error {
+	return &ErrorInvalidLengthPrefix{LengthPrefix: lengthPrefix}
+}
+
+// An ErrorInvalidTag is returned when the 'bin' tag doesn't follow the grammar, e.g. a quote isn't closed.
+// The column starts at 1.
+type ErrorInvalidTag struct {
+	Column int
+	Reason string
+}
+
+func (e *ErrorInvalidTag) Error() string {
+	return fmt.Sprintf("invalid tag at column %d: %s", e.Column, e.Reason)
+}
+
+func (e *ErrorInvalidTag) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidTag)
+	return ok
+}
+
+func newInvalidTagError(column int, reason string) error {
+	return &ErrorInvalidTag{Column: column, Reason: reason}
+}
+
+// An ErrorInvalidDefault is returned when the value of the 'default' annotation can't be converted to the type of the field.
+type ErrorInvalidDefault struct {
+	Default string
+}
+
+func (e *ErrorInvalidDefault) Error() string {
+	return fmt.Sprintf("invalid default value given '%s'", e.Default)
+}
+
+func (e *ErrorInvalidDefault) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidDefault)
+	return ok
+}
+
+func newInvalidDefaultError(defaultValue string) error {
+	return &ErrorInvalidDefault{Default: defaultValue}
+}
+
+// An ErrorInvalidSchema is returned when a field of a Schema can't be turned into a struct field.
+type ErrorInvalidSchema struct {
+	Field  string
+	Reason string
+}
+
+func (e *ErrorInvalidSchema) Error() string {
+	return fmt.Sprintf("invalid schema field '%s': %s", e.Field, e.Reason)
+}
+
+func (e *ErrorInvalidSchema) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidSchema)
+	return ok
+}
+
+func newInvalidSchemaError(field string, reason string) error {
+	return &ErrorInvalidSchema{Field: field, Reason: reason}
+}
+
+// An ErrorInvalidRecordValue is returned when a numeric value of a Record doesn't fit into an integer field,
+// as it has a fractional part or is out of range.
+type ErrorInvalidRecordValue struct {
+	Value interface{}
+	Type  reflect.Type
+}
+
+func (e *ErrorInvalidRecordValue) Error() string {
+	return fmt.Sprintf("record value '%v' doesn't fit into type '%s'", e.Value, e.Type)
+}
+
+func (e *ErrorInvalidRecordValue) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidRecordValue)
+	return ok
+}
+
+func newInvalidRecordValueError(value interface{}, valueType reflect.Type) error {
+	return &ErrorInvalidRecordValue{Value: value, Type: valueType}
+}
+
+// An ErrorMisplacedStructAnnotation is returned when an annotation for the whole struct, like 'allowshort',
+// is on a field which is not a blank (_) or struct{} field.
+var ErrorMisplacedStructAnnotation = fmt.Errorf("struct annotation must be on a blank (_) or struct{} field")
+
+// An ErrorInvalidTerminator is returned when the 'terminator' annotation is empty.
+type ErrorInvalidTerminator struct {
+	Terminator string
+}
+
+func (e *ErrorInvalidTerminator) Error() string {
+	return fmt.Sprintf("invalid terminator given '%s'", e.Terminator)
+}
+
+func (e *ErrorInvalidTerminator) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidTerminator)
+	return ok
+}
+
+func newInvalidTerminatorError(terminator string) error {
+	return &ErrorInvalidTerminator{Terminator: terminator}
+}
+
 // An ErrorInvalidAddressAnnotation is returned when an error happens
 // while processing the address annotation.
 type ErrorInvalidAddressAnnotation struct {
@@ -144,6 +337,10 @@ func newInvalidSizeForArrayError(size int) error {
 // An ErrorUnknownFieldName is returned when a struct doesn't have the searched field name.
 var ErrorUnknownFieldName = fmt.Errorf("unknown field name")
 
+// An ErrorUnknownFieldPosition is returned when the referenced array size field comes after the array, but it has
+// no absolute position and the size of the fields after it depends on the data.
+var ErrorUnknownFieldPosition = fmt.Errorf("position of the referenced field can't be determined")
+
 // An ErrorInvalidDynamicArraySize is returned when something went wrong while resolving the array size.
 // Check the underlying error for more information!
 type ErrorInvalidDynamicArraySize struct {
@@ -253,8 +450,242 @@ func newProcessingFieldError(fieldName string, annotations string, err error) er
 	return &ErrorProcessingField{FieldName: fieldName, Annotations: annotations, Err: err}
 }
 
+// An ErrorUnknownAnnotation is returned by the validation when an annotation is not known, probably mistyped.
+type ErrorUnknownAnnotation struct {
+	Annotation string
+}
+
+func (e *ErrorUnknownAnnotation) Error() string {
+	return fmt.Sprintf("unknown annotation '%s'", e.Annotation)
+}
+
+func (e *ErrorUnknownAnnotation) Is(target error) bool {
+	_, ok := target.(*ErrorUnknownAnnotation)
+	return ok
+}
+
+func newUnknownAnnotationError(annotation string) error {
+	return &ErrorUnknownAnnotation{Annotation: annotation}
+}
+
+// An ErrorMissingNullAnnotation is returned when a value needs to be written as null, but the field has no 'null' annotation.
+var ErrorMissingNullAnnotation = fmt.Errorf("null value requires a 'null' annotation")
+
 // An ErrorMissingAddressAnnotation is returned when a non-struct field is missing the address annotation.
 var ErrorMissingAddressAnnotation = fmt.Errorf("non-struct field must have address annotation")
 
 // An ErrorMissingArrayAnnotation is returned when an array field is missing the 'array' annotation.
 var ErrorMissingArrayAnnotation = fmt.Errorf("array fields must have an 'array' annotation")
+
+// An ErrorInvalidSignConvention is returned when the 'sign' annotation has an unknown value.
+type ErrorInvalidSignConvention struct {
+	Sign string
+}
+
+func (e *ErrorInvalidSignConvention) Error() string {
+	return fmt.Sprintf("invalid sign convention given '%s'", e.Sign)
+}
+
+func (e *ErrorInvalidSignConvention) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidSignConvention)
+	return ok
+}
+
+func newInvalidSignConventionError(sign string) error {
+	return &ErrorInvalidSignConvention{Sign: sign}
+}
+
+// An ErrorInvalidSignFormat is returned when a read number doesn't follow the annotated sign convention.
+type ErrorInvalidSignFormat struct {
+	Value string
+	Sign  string
+}
+
+func (e *ErrorInvalidSignFormat) Error() string {
+	return fmt.Sprintf("invalid signed value '%s' for sign convention '%s'", e.Value, e.Sign)
+}
+
+func (e *ErrorInvalidSignFormat) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidSignFormat)
+	return ok
+}
+
+func newInvalidSignFormatError(value string, sign string) error {
+	return &ErrorInvalidSignFormat{Value: value, Sign: sign}
+}
+
+// An ErrorNegativeUnsignedValue is returned when a negative number has to be written into a field without a sign.
+var ErrorNegativeUnsignedValue = fmt.Errorf("negative value in a field without a sign")
+
+// An ErrorConstantMismatch is returned when the read bytes differ from the value of the 'const' annotation.
+type ErrorConstantMismatch struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ErrorConstantMismatch) Error() string {
+	return fmt.Sprintf("constant mismatch - expected '%s' but found '%s'", e.Expected, e.Actual)
+}
+
+func (e *ErrorConstantMismatch) Is(target error) bool {
+	_, ok := target.(*ErrorConstantMismatch)
+	return ok
+}
+
+func newConstantMismatchError(expected string, actual string) error {
+	return &ErrorConstantMismatch{Expected: expected, Actual: actual}
+}
+
+// An ErrorUnknownRecordType is returned when a message doesn't match any of the registered codes of a Dispatcher.
+type ErrorUnknownRecordType struct {
+	Record string
+}
+
+func (e *ErrorUnknownRecordType) Error() string {
+	return fmt.Sprintf("no registered record type matches the message '%s'", e.Record)
+}
+
+func (e *ErrorUnknownRecordType) Is(target error) bool {
+	_, ok := target.(*ErrorUnknownRecordType)
+	return ok
+}
+
+func newUnknownRecordTypeError(record string) error {
+	return &ErrorUnknownRecordType{Record: record}
+}
+
+// An ErrorEmptyRecordCode is returned when a struct type is registered in a Dispatcher with an empty code.
+var ErrorEmptyRecordCode = fmt.Errorf("record code must not be empty")
+
+// An ErrorNoBytesRead is returned when a Dispatcher decodes a message without reading a single byte of it.
+var ErrorNoBytesRead = fmt.Errorf("message decoded without reading a byte")
+
+// An ErrorInvalidCondition is returned when the 'if' annotation isn't in the form of "<field_name>=<value>" or "<field_name>!=<value>".
+type ErrorInvalidCondition struct {
+	Condition string
+}
+
+func (e *ErrorInvalidCondition) Error() string {
+	return fmt.Sprintf("invalid condition given '%s'", e.Condition)
+}
+
+func (e *ErrorInvalidCondition) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidCondition)
+	return ok
+}
+
+func newInvalidConditionError(condition string) error {
+	return &ErrorInvalidCondition{Condition: condition}
+}
+
+// An ErrorConditionFieldNotBefore is returned when the field referenced by the 'if' annotation doesn't come before the annotated field.
+var ErrorConditionFieldNotBefore = fmt.Errorf("field of the condition must come before the annotated field")
+
+// An ErrorInvalidOverlay is returned when the 'overlay' annotation doesn't refer to an earlier primitive field
+// which is at least as long as the annotated one.
+var ErrorInvalidOverlay = fmt.Errorf("overlay must refer to an earlier primitive field at least as long as the view")
+
+// An ErrorInvalidSeparator is returned when the 'sep' annotation is empty or not on an array.
+var ErrorInvalidSeparator = fmt.Errorf("separator must be a non-empty value on an array")
+
+// An ErrorSeparatorNotFound is returned when the separator is missing between two elements of an array.
+var ErrorSeparatorNotFound = fmt.Errorf("separator not found between array elements")
+
+// An ErrorAmbiguousOverlay is returned when more than one view with the 'primary' annotation has to be written over the same bytes.
+var ErrorAmbiguousOverlay = fmt.Errorf("more than one primary view of the same bytes")
+
+// An ErrorOverlayWithoutCondition is returned when a view without the 'primary' annotation has no 'if' annotation.
+var ErrorOverlayWithoutCondition = fmt.Errorf("view which isn't primary needs a condition")
+
+// An ErrorInvalidChecksum is returned when the 'checksum' annotation isn't in the form of "<algorithm>:<from>-<to>".
+type ErrorInvalidChecksum struct {
+	Checksum string
+}
+
+func (e *ErrorInvalidChecksum) Error() string {
+	return fmt.Sprintf("invalid checksum given '%s'", e.Checksum)
+}
+
+func (e *ErrorInvalidChecksum) Is(target error) bool {
+	_, ok := target.(*ErrorInvalidChecksum)
+	return ok
+}
+
+func newInvalidChecksumError(checksum string) error {
+	return &ErrorInvalidChecksum{Checksum: checksum}
+}
+
+// An ErrorUnknownChecksumAlgorithm is returned when the algorithm of the 'checksum' annotation isn't registered.
+type ErrorUnknownChecksumAlgorithm struct {
+	Algorithm string
+}
+
+func (e *ErrorUnknownChecksumAlgorithm) Error() string {
+	return fmt.Sprintf("unknown checksum algorithm '%s'", e.Algorithm)
+}
+
+func (e *ErrorUnknownChecksumAlgorithm) Is(target error) bool {
+	_, ok := target.(*ErrorUnknownChecksumAlgorithm)
+	return ok
+}
+
+func newUnknownChecksumAlgorithmError(algorithm string) error {
+	return &ErrorUnknownChecksumAlgorithm{Algorithm: algorithm}
+}
+
+// An ErrorChecksumMismatch is returned when the read check value differs from the one computed over the data.
+type ErrorChecksumMismatch struct {
+	Expected uint64
+	Actual   uint64
+}
+
+func (e *ErrorChecksumMismatch) Error() string {
+	return fmt.Sprintf("checksum mismatch - computed '%X' but found '%X'", e.Expected, e.Actual)
+}
+
+func (e *ErrorChecksumMismatch) Is(target error) bool {
+	_, ok := target.(*ErrorChecksumMismatch)
+	return ok
+}
+
+func newChecksumMismatchError(expected uint64, actual uint64) error {
+	return &ErrorChecksumMismatch{Expected: expected, Actual: actual}
+}
+
+// An ErrorLengthMismatch is returned when the value of a length field differs from the number of bytes read.
+type ErrorLengthMismatch struct {
+	Declared int
+	Actual   int
+}
+
+func (e *ErrorLengthMismatch) Error() string {
+	return fmt.Sprintf("length mismatch - declared '%d' but read '%d'", e.Declared, e.Actual)
+}
+
+func (e *ErrorLengthMismatch) Is(target error) bool {
+	_, ok := target.(*ErrorLengthMismatch)
+	return ok
+}
+
+func newLengthMismatchError(declared int, actual int) error {
+	return &ErrorLengthMismatch{Declared: declared, Actual: actual}
+}
+
+// An ErrorArraySizeMismatch is returned when the value of the array size field differs from the length of the slice.
+type ErrorArraySizeMismatch struct {
+	Declared int
+	Actual   int
+}
+
+func (e *ErrorArraySizeMismatch) Error() string {
+	return fmt.Sprintf("array size mismatch - declared '%d' but the slice has '%d' elements", e.Declared, e.Actual)
+}
+
+func (e *ErrorArraySizeMismatch) Is(target error) bool {
+	_, ok := target.(*ErrorArraySizeMismatch)
+	return ok
+}
+
+func newArraySizeMismatchError(declared int, actual int) error {
+	return &ErrorArraySizeMismatch{Declared: declared, Actual: actual}
+}
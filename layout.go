@@ -0,0 +1,215 @@
+package binfile
+
+import (
+	"reflect"
+)
+
+// A FieldLayout describes where an annotated field lands in the byte array.
+type FieldLayout struct {
+	// The dot separated path of the field from the top-level struct. Array elements are marked with "[]".
+	Path        string
+	Name        string
+	Type        reflect.Type
+	Annotations []string
+
+	// The position of the field counted from the start of the message or -1 if it's not static.
+	Offset int
+	// The number of bytes the field takes up or -1 if it depends on the data.
+	Length int
+	// True if the position of the field doesn't depend on the contents of arrays before it.
+	IsStatic bool
+
+	// The fields of a nested struct or the fields of the first element of an array of structs.
+	Fields []FieldLayout
+}
+
+// Accepts an annotated struct type, a slice of structs or a pointer to them and computes where each annotated field lands.
+//
+// Returns the layout of the fields as a tree or an error if an annotation is invalid.
+func Layout(recordType reflect.Type) ([]FieldLayout, error) {
+	return LayoutWithTagKey(recordType, defaultTagKey)
+}
+
+// Works like Layout, but the annotations are read from the tags with the provided key, e.g. "bin_v2".
+// See MarshalWithTagKey.
+func LayoutWithTagKey(recordType reflect.Type, tagKey string) ([]FieldLayout, error) {
+
+	var originalType = recordType
+	for recordType != nil && (recordType.Kind() == reflect.Ptr || recordType.Kind() == reflect.Slice) {
+		recordType = recordType.Elem()
+	}
+
+	if recordType == nil || recordType.Kind() != reflect.Struct {
+		return nil, newUnsupportedTypeError(originalType)
+	}
+
+	if err := getStructPlan(recordType, tagKey).firstError(false); err != nil {
+		return nil, err
+	}
+
+	var layouts, _, _ = layoutStruct(recordType, tagKey, "", 0, true, map[reflect.Type]bool{})
+	return layouts, nil
+}
+
+// Computes the layout of every field in the struct type recursively. The struct starts at 'startOffset'
+// which is only valid if 'isStartStatic' is true. 'pathTypes' holds the struct types enclosing the current one;
+// a recursive type (e.g. an array of itself) is not expanded again and has no static size.
+//
+// Returns the layouts, the size of the struct and a bool which is true if the size doesn't depend on the data.
+func layoutStruct(recordType reflect.Type, tagKey string, pathPrefix string, startOffset int, isStartStatic bool, pathTypes map[reflect.Type]bool) ([]FieldLayout, int, bool) {
+
+	if pathTypes[recordType] {
+		return nil, 0, false
+	}
+	pathTypes[recordType] = true
+	defer delete(pathTypes, recordType)
+
+	var plan = getStructPlan(recordType, tagKey)
+	var layouts []FieldLayout
+	var currentPos = 0
+	var isStatic = true
+	var fieldOffsets = newFieldPositions(len(plan.fields)) // for the views of overlays
+
+	for fieldNo := range plan.fields {
+
+		var field = &plan.fields[fieldNo]
+		var structField = recordType.Field(fieldNo)
+
+		if !structField.IsExported() && !field.isConstant {
+			continue
+		}
+		if field.valueKind != reflect.Struct && !field.hasAnnotations {
+			continue // unannotated fields are not processed
+		}
+
+		if field.isRootRelative {
+			currentPos, isStatic = field.absoluteAnnotatedPos-startOffset, isStartStatic
+		} else if field.absoluteAnnotatedPos != -1 {
+			currentPos, isStatic = field.absoluteAnnotatedPos, true
+		}
+
+		var layout = FieldLayout{
+			Path:        pathPrefix + field.name,
+			Name:        field.name,
+			Type:        structField.Type,
+			Annotations: field.annotationList,
+			Offset:      -1,
+			Length:      -1,
+			IsStatic:    isStartStatic && isStatic,
+		}
+		if layout.IsStatic {
+			layout.Offset = startOffset + currentPos
+		}
+		if field.isRootRelative { // known even if the start of the struct isn't
+			layout.Offset, layout.IsStatic = field.absoluteAnnotatedPos, true
+		}
+		if field.hasOverlay { // a view starts where the overlaid field starts and takes up no extra space
+			layout.Offset, layout.IsStatic, layout.Length = fieldOffsets[field.overlayFieldNo], fieldOffsets[field.overlayFieldNo] != -1, field.relativeAnnotatedLength
+			layouts = append(layouts, layout)
+			continue
+		}
+		fieldOffsets[fieldNo] = layout.Offset
+
+		switch {
+		case field.isConstant:
+
+			layout.Length = field.relativeAnnotatedLength
+
+		case field.valueKind == reflect.Struct:
+
+			var size int
+			var isSizeStatic bool
+			layout.Fields, size, isSizeStatic = layoutStruct(structField.Type, tagKey, layout.Path+".", layout.Offset, layout.IsStatic, pathTypes)
+			if isSizeStatic {
+				layout.Length = size
+			}
+
+		case field.valueKind == reflect.Slice:
+
+			var elemType = structField.Type.Elem()
+			var elemSize = field.relativeAnnotatedLength
+			var isElemStatic = true
+			if elemType.Kind() == reflect.Struct {
+				layout.Fields, elemSize, isElemStatic = layoutStruct(elemType, tagKey, layout.Path+"[].", layout.Offset, layout.IsStatic, pathTypes)
+			}
+
+			if field.isFixedSize && isElemStatic {
+				layout.Length = field.fixedArraySize(elemSize)
+			}
+
+		default:
+
+			layout.Length = field.relativeAnnotatedLength
+		}
+
+		if field.hasLenPrefix {
+			layout.Length = -1
+		}
+
+		if layout.Length == -1 || field.hasCondition {
+			isStatic = false
+		} else {
+			currentPos += layout.Length
+		}
+
+		layouts = append(layouts, layout)
+	}
+
+	return layouts, currentPos, isStatic
+}
+
+// Computes the size of the fields from 'fromFieldNo' to the end of the struct type.
+// Returns the size and a bool which is false if it depends on the data or on an absolute position.
+func sizeOfTrailingFields(recordType reflect.Type, tagKey string, fromFieldNo int) (int, bool) {
+
+	var plan = getStructPlan(recordType, tagKey)
+	var pathTypes = map[reflect.Type]bool{recordType: true}
+	var size = 0
+
+	for fieldNo := fromFieldNo; fieldNo < len(plan.fields); fieldNo++ {
+
+		var field = &plan.fields[fieldNo]
+		if !field.isConstant && (!recordType.Field(fieldNo).IsExported() || (field.valueKind != reflect.Struct && !field.hasAnnotations)) {
+			continue
+		}
+		if field.absoluteAnnotatedPos != -1 || field.hasLenPrefix || field.hasCondition {
+			return 0, false
+		}
+
+		switch {
+		case field.hasOverlay:
+
+			continue // takes up no extra space
+
+		case field.isConstant:
+
+			size += field.relativeAnnotatedLength
+
+		case field.valueKind == reflect.Struct:
+
+			var _, structSize, isStatic = layoutStruct(recordType.Field(fieldNo).Type, tagKey, "", 0, true, pathTypes)
+			if !isStatic {
+				return 0, false
+			}
+			size += structSize
+
+		case field.valueKind == reflect.Slice:
+
+			var elemType = recordType.Field(fieldNo).Type.Elem()
+			var elemSize, isElemStatic = field.relativeAnnotatedLength, true
+			if elemType.Kind() == reflect.Struct {
+				_, elemSize, isElemStatic = layoutStruct(elemType, tagKey, "", 0, true, pathTypes)
+			}
+			if !field.isFixedSize || !isElemStatic {
+				return 0, false
+			}
+			size += field.fixedArraySize(elemSize)
+
+		default:
+
+			size += field.relativeAnnotatedLength
+		}
+	}
+
+	return size, true
+}
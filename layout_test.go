@@ -0,0 +1,130 @@
+package binfile
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+//-Layout----------------------------------------------------------------------
+
+type testLayout struct {
+	RecordType string `bin:":2"`
+	Nested     struct {
+		UnitNo int `bin:":2"`
+	}
+	FixedArray []testLayoutInner `bin:"array:2"`
+	Gap        string            `bin:"12:1,trim"`
+	Results    []testLayoutInner `bin:"array:terminator"`
+	AfterArray string            `bin:":1"`
+	Absolute   string            `bin:"20:1"`
+}
+
+type testLayoutInner struct {
+	Code  string `bin:":1"`
+	Value int    `bin:":2"`
+}
+
+func TestLayout(t *testing.T) {
+
+	layouts, err := Layout(reflect.TypeOf(testLayout{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(layouts))
+
+	assert.Equal(t, "RecordType", layouts[0].Path)
+	assert.Equal(t, 0, layouts[0].Offset)
+	assert.Equal(t, 2, layouts[0].Length)
+	assert.Equal(t, reflect.TypeOf(""), layouts[0].Type)
+
+	assert.Equal(t, 2, layouts[1].Offset)
+	assert.Equal(t, 2, layouts[1].Length)
+	assert.Equal(t, "Nested.UnitNo", layouts[1].Fields[0].Path)
+	assert.Equal(t, 2, layouts[1].Fields[0].Offset)
+
+	assert.Equal(t, 4, layouts[2].Offset)
+	assert.Equal(t, 6, layouts[2].Length)
+	assert.Equal(t, "FixedArray[].Value", layouts[2].Fields[1].Path)
+	assert.Equal(t, 5, layouts[2].Fields[1].Offset)
+
+	assert.Equal(t, 12, layouts[3].Offset)
+	assert.Equal(t, []string{"12:1", "trim"}, layouts[3].Annotations)
+
+	assert.Equal(t, 13, layouts[4].Offset)
+	assert.Equal(t, -1, layouts[4].Length)
+	assert.Equal(t, true, layouts[4].IsStatic)
+	assert.Equal(t, 13, layouts[4].Fields[0].Offset)
+
+	assert.Equal(t, false, layouts[5].IsStatic)
+	assert.Equal(t, -1, layouts[5].Offset)
+	assert.Equal(t, 1, layouts[5].Length)
+
+	// an absolute position is static again
+	assert.Equal(t, true, layouts[6].IsStatic)
+	assert.Equal(t, 20, layouts[6].Offset)
+
+	//-------------------------------------------------------------------------
+
+	layouts, err = Layout(reflect.TypeOf(testRootPositionMarshal{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, layouts[1].Fields[1].Offset)
+	assert.Equal(t, 10, layouts[1].Fields[2].Offset)
+	assert.Equal(t, 14, layouts[2].Offset)
+
+	//-------------------------------------------------------------------------
+
+	// the views of an overlay take up no extra space
+	layouts, err = Layout(reflect.TypeOf(testOverlayUnmarshal{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, layouts[2].Offset)
+	assert.Equal(t, 4, layouts[3].Length)
+	assert.Equal(t, 1, layouts[3].Offset)
+	assert.Equal(t, 7, layouts[4].Offset)
+
+	//-------------------------------------------------------------------------
+
+	// the separators are part of a fixed size array
+	layouts, err = Layout(reflect.TypeOf(testSeparatorMarshal{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, layouts[1].Length)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Layout(reflect.TypeOf(testCodecInvalidNested{}))
+	assert.Equal(t, true, errors.Is(err, ErrorMissingArrayAnnotation))
+}
+
+func TestLayoutRecursiveType(t *testing.T) {
+
+	// a type containing itself is not expanded again
+	layouts, err := Layout(reflect.TypeOf(testCodecRecursive{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(layouts))
+	assert.Equal(t, -1, layouts[1].Length)
+	assert.Nil(t, layouts[1].Fields)
+}
+
+func TestLayoutWithTagKey(t *testing.T) {
+
+	layouts, err := LayoutWithTagKey(reflect.TypeOf(testTagKeyMarshal{}), "bin_v2")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(layouts)) // the filler is left out
+
+	assert.Equal(t, "Value", layouts[2].Path)
+	assert.Equal(t, 7, layouts[2].Offset)
+	assert.Equal(t, 4, layouts[2].Length)
+	assert.Equal(t, "Flag", layouts[3].Path)
+	assert.Equal(t, 11, layouts[3].Offset)
+	assert.Equal(t, "Nested.Code", layouts[4].Fields[0].Path)
+	assert.Equal(t, 3, layouts[4].Fields[0].Length)
+
+	//-------------------------------------------------------------------------
+
+	layouts, err = Layout(reflect.TypeOf(testTagKeyMarshal{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(layouts)) // the flag is left out
+	assert.Equal(t, "Filler", layouts[3].Path)
+	assert.Equal(t, 8, layouts[3].Offset)
+}
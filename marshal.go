@@ -1,6 +1,8 @@
 package binfile
 
 import (
+	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 )
@@ -11,10 +13,16 @@ import (
 //
 // Check the README.md for usage.
 func Marshal(target interface{}, padding byte, enc Encoding, tz Timezone, arrayTerminator string) ([]byte, error) {
+	return MarshalWithTagKey(target, defaultTagKey, padding, enc, tz, arrayTerminator)
+}
+
+// Works like Marshal, but the annotations are read from the tags with the provided key, e.g. "bin_v2".
+// A field without such a tag falls back to its 'bin' tag, so one struct can describe several layouts.
+func MarshalWithTagKey(target interface{}, tagKey string, padding byte, enc Encoding, tz Timezone, arrayTerminator string) ([]byte, error) {
 
 	// TODO: accepting a Ptr here is confusing as the func will not change the contents
 	if reflect.TypeOf(target).Kind() == reflect.Ptr {
-		return Marshal(reflect.ValueOf(target).Elem(), padding, enc, tz, arrayTerminator)
+		return MarshalWithTagKey(reflect.ValueOf(target).Elem(), tagKey, padding, enc, tz, arrayTerminator)
 	}
 
 	var outBytes []byte
@@ -36,7 +44,7 @@ func Marshal(target interface{}, padding byte, enc Encoding, tz Timezone, arrayT
 				// TODO: slice of slices?
 
 			case reflect.Struct:
-				tempBytes, _, err = internalMarshal(targetValue.Index(i), false, padding, arrayTerminator, 0, depth+1)
+				tempBytes, _, err = internalMarshal(targetValue.Index(i), false, padding, arrayTerminator, tagKey, 0, depth+1)
 				if err != nil {
 					return []byte{}, err
 				}
@@ -53,7 +61,7 @@ func Marshal(target interface{}, padding byte, enc Encoding, tz Timezone, arrayT
 		return outBytes, err
 
 	case reflect.Struct:
-		outBytes, _, err = internalMarshal(targetValue, false, padding, arrayTerminator, 0, depth)
+		outBytes, _, err = internalMarshal(targetValue, false, padding, arrayTerminator, tagKey, 0, depth)
 		return outBytes, err
 
 	}
@@ -62,36 +70,74 @@ func Marshal(target interface{}, padding byte, enc Encoding, tz Timezone, arrayT
 }
 
 // use this for recursion
-func internalMarshal(record reflect.Value, onlyPaddWithZeros bool, padding byte, arrayTerminator string, currentByte int, depth int) ([]byte, int, error) {
+func internalMarshal(record reflect.Value, onlyPaddWithZeros bool, padding byte, arrayTerminator string, tagKey string, currentByte int, depth int) ([]byte, int, error) {
 
 	outBytes := []byte{}
 
+	var initialStartByte = currentByte
+
+	var plan = getStructPlan(record.Type(), tagKey)
+	if plan.hasTerminator {
+		arrayTerminator = plan.terminator
+	}
+
+	// the start and end of every written field and if a primary view was written over it,
+	// needed for overlays, checksums and length fields
+	var fieldStartBytes, fieldEndBytes []int
+	var isOverlaid []bool
+	if plan.tracksFieldPositions() {
+		fieldStartBytes, fieldEndBytes, isOverlaid = newFieldPositions(record.NumField()), newFieldPositions(record.NumField()), make([]bool, record.NumField())
+	}
+	var lastFieldNo = -1
+
 	for fieldNo := 0; fieldNo < record.NumField(); fieldNo++ {
 
 		var recordField = record.Field(fieldNo)
+		var field = &plan.fields[fieldNo]
 
-		var binTag = record.Type().Field(fieldNo).Tag.Get("bin")
-		if !recordField.CanInterface() {
-			if binTag != "" {
-				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorExportedFieldNotAnnotated)
-			} else {
-				continue // TODO: this won't notify you about accidentally not exported nested structs
-			}
+		if fieldEndBytes != nil && lastFieldNo != -1 {
+			fieldEndBytes[lastFieldNo], lastFieldNo = currentByte, -1
 		}
 
-		var annotationList, hasAnnotations = getAnnotationList(binTag)
-
-		absoluteAnnotatedPos, relativeAnnotatedLength, hasAnnotatedAddress, err := getAddressAnnotation(annotationList)
-		if err != nil {
-			return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidAddressAnnotationError(err))
+		if field.err != nil {
+			return []byte{}, currentByte, field.err
 		}
+		if field.hasCondition && !isConditionMet(record, field) {
+			continue // the field doesn't exist in this record
+		}
+
+		var binTag = field.binTag
+		var hasAnnotations = field.hasAnnotations
+		var absoluteAnnotatedPos, relativeAnnotatedLength = field.absoluteAnnotatedPos, field.relativeAnnotatedLength
+		var err error
 
 		if absoluteAnnotatedPos != -1 {
-			if currentByte < absoluteAnnotatedPos {
-				outBytes, currentByte = appendPaddingBytes(outBytes, absoluteAnnotatedPos-currentByte, padding)
-			} else if currentByte > absoluteAnnotatedPos {
-				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidInvalidOffsetError(currentByte, absoluteAnnotatedPos))
+			// the position is counted from the start of the current struct or with '@' from the start of the message
+			var targetPos = initialStartByte + absoluteAnnotatedPos
+			if field.isRootRelative {
+				targetPos = absoluteAnnotatedPos
+			}
+			if currentByte < targetPos {
+				var paddingBytes []byte
+				paddingBytes, _ = appendPaddingBytes([]byte{}, targetPos-currentByte, padding)
+				outBytes, currentByte = append(outBytes, paddingBytes...), targetPos
+			} else if currentByte > targetPos {
+				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidInvalidOffsetError(currentByte, targetPos))
+			}
+		}
+
+		if field.isConstant { // written without the value of the field, so it can be unexported
+			if onlyPaddWithZeros {
+				outBytes = append(outBytes, make([]byte, relativeAnnotatedLength)...)
+			} else {
+				outBytes = append(outBytes, field.constValue...)
 			}
+			currentByte += relativeAnnotatedLength
+			continue
+		}
+
+		if !recordField.CanInterface() {
+			continue
 		}
 		/*
 			for k := 0; k < depth; k++ {
@@ -101,12 +147,16 @@ func internalMarshal(record reflect.Value, onlyPaddWithZeros bool, padding byte,
 				record.Type().Field(fieldNo).Name,
 				absoluteAnnotatedPos, relativeAnnotatedLength, currentByte)*/
 
-		var valueKind = reflect.TypeOf(recordField.Interface()).Kind()
+		if fieldStartBytes != nil {
+			fieldStartBytes[fieldNo], lastFieldNo = currentByte, fieldNo
+		}
+
+		var valueKind = field.valueKind
 		if valueKind == reflect.Struct {
 
 			var tempOutByte []byte
 			var err error
-			tempOutByte, currentByte, err = internalMarshal(recordField, onlyPaddWithZeros, padding, arrayTerminator, currentByte, depth+1)
+			tempOutByte, currentByte, err = internalMarshal(recordField, onlyPaddWithZeros, padding, arrayTerminator, tagKey, currentByte, depth+1)
 			if err != nil { // If the nested structure did fail, then bail out
 				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
 			}
@@ -120,30 +170,68 @@ func internalMarshal(record reflect.Value, onlyPaddWithZeros bool, padding byte,
 			continue // Do not process unannotated fields
 		}
 
-		if valueKind == reflect.Slice {
+		if field.hasOverlay { // by default the overlaid field is written and the views are ignored
+			if !field.isPrimaryView || onlyPaddWithZeros {
+				continue
+			}
+			if isOverlaid[field.overlayFieldNo] {
+				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorAmbiguousOverlay)
+			}
+			if fieldStartBytes[field.overlayFieldNo] == -1 {
+				continue // the overlaid field isn't in this record
+			}
+			var overlaidBytes = outBytes[fieldStartBytes[field.overlayFieldNo]-initialStartByte:]
+			if err = marshalOverlayView(overlaidBytes, recordField, field, plan.fields[field.overlayFieldNo].relativeAnnotatedLength, padding, depth); err != nil {
+				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
+			}
+			isOverlaid[field.overlayFieldNo] = true
+			continue
+		}
+
+		if field.hasLenPrefix && (valueKind == reflect.String || isByteSliceType(recordField.Type())) {
 
-			var arrayAnnotation, hasArrayAnnotation = getArrayAnnotation(annotationList)
-			if !hasArrayAnnotation {
-				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorMissingArrayAnnotation)
+			var tempOutByte []byte
+			if tempOutByte, err = marshalLengthPrefixed(recordField, field); err != nil {
+				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
 			}
+			outBytes = append(outBytes, tempOutByte...)
+			currentByte += len(tempOutByte)
+
+			continue
+		}
+
+		if valueKind == reflect.Slice {
 
 			var sliceValue = reflect.ValueOf(recordField.Interface())
 			var innerValueKind = reflect.TypeOf(recordField.Interface()).Elem().Kind()
 
-			if innerValueKind != reflect.Struct && !hasAnnotatedAddress {
-				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorMissingAddressAnnotation)
-			}
-
 			var arraySize = sliceValue.Len()
-			var isTerminatorType = isArrayTypeTerminator(arrayAnnotation)
-			if !isTerminatorType {
-				if size, isFixedSize := getArrayFixedSize(arrayAnnotation); isFixedSize {
-					arraySize = size
-				} else if fieldName, isDynamic := getArraySizeFieldName(arrayAnnotation); isDynamic {
-					arraySize, err = resolveDynamicArraySize(record, fieldName)
-					if err != nil {
-						return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidDynamicArraySizeError(record.Type().Name(), fieldName, err))
-					}
+			var isTerminatorType = field.isTerminatorType
+			var fieldTerminator = arrayTerminator
+			if field.hasTerminator {
+				fieldTerminator = field.terminator
+			}
+			if field.hasLenPrefix { // the prefix holds the number of elements
+				var prefixBytes []byte
+				if prefixBytes, err = formatLengthPrefix(arraySize, field.lenPrefixWidth, field.isLenPrefixBinary); err != nil {
+					return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
+				}
+				outBytes = append(outBytes, prefixBytes...)
+				currentByte += len(prefixBytes)
+			} else if field.isFixedSize {
+				arraySize = field.arrayFixedSize
+			} else if field.isDynamicSize && field.isStrictSize {
+				arraySize, err = resolveDynamicArraySize(record, field.arraySizeFieldName)
+				if err == nil && arraySize != recordField.Len() {
+					err = newArraySizeMismatchError(arraySize, recordField.Len())
+				}
+				if err != nil {
+					return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidDynamicArraySizeError(record.Type().Name(), field.arraySizeFieldName, err))
+				}
+			} else if field.isDynamicSize {
+				// the size field is filled from the length of the slice after the loop
+				if _, err = resolveDynamicArraySizeField(record, field.arraySizeFieldName); err != nil {
+					return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidDynamicArraySizeError(record.Type().Name(), field.arraySizeFieldName, err))
 				}
 			}
 
@@ -159,10 +247,15 @@ func internalMarshal(record reflect.Value, onlyPaddWithZeros bool, padding byte,
 					onlyPaddWithZeros = true
 				}
 
+				if i > 0 && field.hasSeparator {
+					outBytes = append(outBytes, field.separator...)
+					currentByte += len(field.separator)
+				}
+
 				switch innerValueKind {
 				case reflect.Struct:
 
-					tempOutByte, currentByte, err = internalMarshal(currentElement, onlyPaddWithZeros, padding, arrayTerminator, currentByte, depth+1)
+					tempOutByte, currentByte, err = internalMarshal(currentElement, onlyPaddWithZeros, padding, fieldTerminator, tagKey, currentByte, depth+1)
 					if err != nil {
 						return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
 					}
@@ -170,7 +263,7 @@ func internalMarshal(record reflect.Value, onlyPaddWithZeros bool, padding byte,
 
 				default:
 
-					tempOutByte, currentByte, err = marshalSimpleTypes(currentElement, onlyPaddWithZeros, relativeAnnotatedLength, annotationList, currentByte, depth)
+					tempOutByte, currentByte, err = marshalSimpleTypes(currentElement, onlyPaddWithZeros, relativeAnnotatedLength, field, currentByte, depth)
 					if err != nil {
 						return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
 					}
@@ -182,19 +275,28 @@ func internalMarshal(record reflect.Value, onlyPaddWithZeros bool, padding byte,
 
 			// TODO: why do we need the terminator in the 2nd case here?
 			if isTerminatorType || sliceValue.Len() > arraySize {
-				outBytes = append(outBytes, arrayTerminator...)
-				currentByte += len(arrayTerminator)
+				outBytes = append(outBytes, fieldTerminator...)
+				currentByte += len(fieldTerminator)
 			}
 
 			continue
 		}
 
-		if !hasAnnotatedAddress {
-			return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorMissingAddressAnnotation)
+		var tempOutByte []byte
+		if field.hasNullFlag && record.Field(field.nullFlagFieldNo).Bool() {
+			if tempOutByte, err = marshalNullSentinel(field, relativeAnnotatedLength); err != nil {
+				return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
+			}
+			outBytes = append(outBytes, tempOutByte...)
+			currentByte += relativeAnnotatedLength
+			continue
 		}
 
-		var tempOutByte []byte
-		tempOutByte, currentByte, err = marshalSimpleTypes(recordField, onlyPaddWithZeros, relativeAnnotatedLength, annotationList, currentByte, depth)
+		if field.hasDefault && recordField.IsZero() { // a nil pointer gets the default too
+			recordField = field.defaultValue
+		}
+
+		tempOutByte, currentByte, err = marshalSimpleTypes(recordField, onlyPaddWithZeros, relativeAnnotatedLength, field, currentByte, depth)
 		if err != nil {
 			return []byte{}, currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
 		}
@@ -202,11 +304,112 @@ func internalMarshal(record reflect.Value, onlyPaddWithZeros bool, padding byte,
 
 	}
 
+	if fieldEndBytes != nil && lastFieldNo != -1 {
+		fieldEndBytes[lastFieldNo] = currentByte
+	}
+
+	// the array sizes are filled after the other fields are laid out, as the size field can be after the array
+	if plan.hasDerivedSizes && !onlyPaddWithZeros {
+		for fieldNo := range plan.fields {
+			var field = &plan.fields[fieldNo]
+			if !field.isDynamicSize || field.isStrictSize || field.arraySizeFieldNo == -1 ||
+				fieldStartBytes[fieldNo] == -1 || fieldStartBytes[field.arraySizeFieldNo] == -1 {
+				continue
+			}
+			var sizeField = &plan.fields[field.arraySizeFieldNo]
+			if err := marshalComputedInt(outBytes, fieldStartBytes[field.arraySizeFieldNo]-initialStartByte, record.Field(fieldNo).Len(), sizeField, depth); err != nil {
+				return []byte{}, currentByte, newProcessingFieldError(sizeField.name, sizeField.binTag, err)
+			}
+		}
+	}
+
+	// the lengths are filled after the other fields are laid out, as they can be of fields after them
+	if plan.hasLengths && !onlyPaddWithZeros {
+		for fieldNo := range plan.fields {
+			var field = &plan.fields[fieldNo]
+			if !field.hasLength || fieldStartBytes[fieldNo] == -1 {
+				continue
+			}
+			if err := marshalComputedInt(outBytes, fieldStartBytes[fieldNo]-initialStartByte, measureLength(field, initialStartByte, currentByte, fieldStartBytes, fieldEndBytes), field, depth); err != nil {
+				return []byte{}, currentByte, newProcessingFieldError(field.name, field.binTag, err)
+			}
+		}
+	}
+
+	// the check values are filled after the other fields are laid out, as their range can be anywhere in the struct
+	if plan.hasChecksums && !onlyPaddWithZeros {
+		for fieldNo := range plan.fields {
+			if !plan.fields[fieldNo].hasChecksum || fieldStartBytes[fieldNo] == -1 {
+				continue
+			}
+			if err := marshalChecksum(outBytes, fieldStartBytes[fieldNo]-initialStartByte, record.Field(fieldNo), &plan.fields[fieldNo], depth); err != nil {
+				return []byte{}, currentByte, newProcessingFieldError(plan.fields[fieldNo].name, plan.fields[fieldNo].binTag, err)
+			}
+		}
+	}
+
 	return outBytes, currentByte, nil
 }
 
-// use this for processing end nodes
-func marshalSimpleTypes(recordField reflect.Value, onlyPaddWithZeros bool, relativeAnnotatedLength int, annotationList []string, currentByte int, depth int) ([]byte, int, error) {
+// Writes a computed value of an integer field (a length or an array size) at 'fieldStartByte' in 'structBytes'.
+func marshalComputedInt(structBytes []byte, fieldStartByte int, length int, field *fieldPlan, depth int) error {
+
+	var valueBytes, _, err = marshalSimpleTypes(reflect.ValueOf(length), false, field.relativeAnnotatedLength, field, 0, depth)
+	if err != nil {
+		return err
+	}
+
+	copy(structBytes[fieldStartByte:], valueBytes)
+
+	return nil
+}
+
+// Computes the check value of the field over 'structBytes' and writes it at 'fieldStartByte'.
+// A string field gets the value as upper case hex digits, an integer as a decimal number.
+func marshalChecksum(structBytes []byte, fieldStartByte int, recordField reflect.Value, field *fieldPlan, depth int) error {
+
+	var checkValue, err = computeChecksum(structBytes, fieldStartByte, field)
+	if err != nil {
+		return err
+	}
+
+	var valueBytes []byte
+	if recordField.Kind() == reflect.String {
+		var hexValue = fmt.Sprintf("%0*X", field.relativeAnnotatedLength, checkValue)
+		if len(hexValue) > field.relativeAnnotatedLength {
+			return newInvalidValueLengthError(hexValue, field.relativeAnnotatedLength)
+		}
+		valueBytes = []byte(hexValue)
+	} else {
+		var checkField = reflect.New(recordField.Type()).Elem()
+		checkField.SetInt(int64(checkValue))
+		if valueBytes, _, err = marshalSimpleTypes(checkField, false, field.relativeAnnotatedLength, field, 0, depth); err != nil {
+			return err
+		}
+	}
+
+	copy(structBytes[fieldStartByte:], valueBytes)
+
+	return nil
+}
+
+// Writes the value of a primary overlay view at the start of 'overlaidBytes', which are the bytes of the overlaid field.
+// The rest of the overlaid bytes are filled with the padding.
+func marshalOverlayView(overlaidBytes []byte, recordField reflect.Value, field *fieldPlan, overlaidLength int, padding byte, depth int) error {
+
+	var viewBytes, _, err = marshalSimpleTypes(recordField, false, field.relativeAnnotatedLength, field, 0, depth)
+	if err != nil {
+		return err
+	}
+
+	viewBytes, _ = appendPaddingBytes(viewBytes, overlaidLength-len(viewBytes), padding)
+	copy(overlaidBytes, viewBytes)
+
+	return nil
+}
+
+// use this for processing end nodes. The value is formatted according to the processed annotations of the field plan.
+func marshalSimpleTypes(recordField reflect.Value, onlyPaddWithZeros bool, relativeAnnotatedLength int, field *fieldPlan, currentByte int, depth int) ([]byte, int, error) {
 
 	if onlyPaddWithZeros {
 		return make([]byte, relativeAnnotatedLength), currentByte + relativeAnnotatedLength, nil
@@ -215,6 +418,30 @@ func marshalSimpleTypes(recordField reflect.Value, onlyPaddWithZeros bool, relat
 	var outBytes = []byte{}
 
 	var valueKind = reflect.TypeOf(recordField.Interface()).Kind()
+
+	var isNull = (valueKind == reflect.Ptr && recordField.IsNil()) ||
+		((valueKind == reflect.Float32 || valueKind == reflect.Float64) && math.IsNaN(recordField.Float()))
+	if isNull && len(field.nullPatterns) > 0 {
+		var tempBytes, err = marshalNullSentinel(field, relativeAnnotatedLength)
+		if err != nil {
+			return []byte{}, currentByte, err
+		}
+		return tempBytes, currentByte + relativeAnnotatedLength, nil
+	}
+
+	if valueKind == reflect.Ptr {
+		if recordField.IsNil() { // a missing value has no other representation than blanks
+			outBytes, _ = appendPaddingBytes(outBytes, relativeAnnotatedLength, byte(' '))
+			return outBytes, currentByte + relativeAnnotatedLength, nil
+		}
+		return marshalSimpleTypes(recordField.Elem(), onlyPaddWithZeros, relativeAnnotatedLength, field, currentByte, depth)
+	}
+
+	if isNumericKind(valueKind) && recordField.IsZero() && field.isBlankZero {
+		outBytes, _ = appendPaddingBytes(outBytes, relativeAnnotatedLength, byte(' '))
+		return outBytes, currentByte + relativeAnnotatedLength, nil
+	}
+
 	switch valueKind {
 	case reflect.String:
 
@@ -236,34 +463,22 @@ func marshalSimpleTypes(recordField reflect.Value, onlyPaddWithZeros bool, relat
 			return []byte{}, currentByte, ErrorIntConversionOverflow
 		}
 
-		var isSignForced = hasAnnotationForceSign(annotationList)
-		var isNegative = tempInt < 0
-		if isNegative {
-			outBytes = append(outBytes, '-')
-		} else if isSignForced {
-			outBytes = append(outBytes, '+')
+		var err error
+		if field.isScaled {
+			if tempInt, err = scaledValueToInt(scaleToWire(float64(tempInt), field.scale, field.offset)); err != nil {
+				return []byte{}, currentByte, err
+			}
 		}
 
-		var tempBytes = []byte(strconv.Itoa(tempInt))
+		var isNegative = tempInt < 0
+		var tempStr = strconv.Itoa(tempInt)
 		if isNegative { // handle negative sign separately
-			tempBytes = tempBytes[1:]
-		}
-
-		var currLength = len(tempBytes)
-		if isNegative || isSignForced {
-			currLength++
+			tempStr = tempStr[1:]
 		}
 
-		if currLength > relativeAnnotatedLength {
-			return []byte{}, currentByte, newInvalidValueLengthError(string(append(outBytes, tempBytes...)), currLength)
-		} else if currLength < relativeAnnotatedLength {
-			var paddingByte byte
-			if hasAnnotationPadspace(annotationList) {
-				paddingByte = byte(' ')
-			} else {
-				paddingByte = byte('0')
-			}
-			outBytes, _ = appendPaddingBytes(outBytes, relativeAnnotatedLength-currLength, paddingByte)
+		var tempBytes []byte
+		if tempBytes, err = formatSignedNumber(tempStr, isNegative, field, relativeAnnotatedLength); err != nil {
+			return []byte{}, currentByte, err
 		}
 
 		outBytes = append(outBytes, tempBytes...)
@@ -271,18 +486,26 @@ func marshalSimpleTypes(recordField reflect.Value, onlyPaddWithZeros bool, relat
 
 	case reflect.Float32, reflect.Float64:
 
-		var precision = -1
-		var err error
-		if precision, err = getPrecisionFromAnnotation(annotationList); err != nil {
-			return []byte{}, currentByte, err
+		var tempFloat = recordField.Float()
+		if field.isScaled {
+			tempFloat = scaleToWire(tempFloat, field.scale, field.offset)
 		}
 
-		var tempFloat = recordField.Float()
 		var tempStr string
-		if valueKind == reflect.Float32 {
-			tempStr = strconv.FormatFloat(tempFloat, 'f', precision, 32)
+		var err error
+		if field.hasRoundingMode || field.isExact {
+			if valueKind == reflect.Float32 {
+				tempStr, err = formatRoundedFloat(tempFloat, 'f', field.precision, 32, field.roundingMode, field.isExact)
+			} else {
+				tempStr, err = formatRoundedFloat(tempFloat, 'E', field.precision, 64, field.roundingMode, field.isExact)
+			}
+			if err != nil {
+				return []byte{}, currentByte, err
+			}
+		} else if valueKind == reflect.Float32 {
+			tempStr = strconv.FormatFloat(tempFloat, 'f', field.precision, 32)
 		} else {
-			tempStr = strconv.FormatFloat(tempFloat, 'E', precision, 64)
+			tempStr = strconv.FormatFloat(tempFloat, 'E', field.precision, 64)
 		}
 		if tempFloat == float64(int(tempFloat)) { // is truly an int?
 			if relativeAnnotatedLength > 1 {
@@ -290,34 +513,14 @@ func marshalSimpleTypes(recordField reflect.Value, onlyPaddWithZeros bool, relat
 			}
 		}
 
-		var isSignForced = hasAnnotationForceSign(annotationList)
 		var isNegative = tempFloat < 0
-		if isNegative {
-			outBytes = append(outBytes, '-')
-		} else if isSignForced {
-			outBytes = append(outBytes, '+')
-		}
-
-		var tempBytes = []byte(tempStr)
 		if isNegative { // handle negative sign separately
-			tempBytes = tempBytes[1:]
+			tempStr = tempStr[1:]
 		}
 
-		var currLength = len(tempBytes)
-		if isNegative || isSignForced {
-			currLength++
-		}
-
-		if currLength > relativeAnnotatedLength {
-			return []byte{}, currentByte, newInvalidValueLengthError(string(append(outBytes, tempBytes...)), currLength)
-		} else if currLength < relativeAnnotatedLength {
-			var paddingByte byte
-			if hasAnnotationPadspace(annotationList) {
-				paddingByte = byte(' ')
-			} else {
-				paddingByte = byte('0')
-			}
-			outBytes, _ = appendPaddingBytes(outBytes, relativeAnnotatedLength-currLength, paddingByte)
+		var tempBytes []byte
+		if tempBytes, err = formatSignedNumber(tempStr, isNegative, field, relativeAnnotatedLength); err != nil {
+			return []byte{}, currentByte, err
 		}
 
 		outBytes = append(outBytes, tempBytes...)
@@ -330,3 +533,122 @@ func marshalSimpleTypes(recordField reflect.Value, onlyPaddWithZeros bool, relat
 
 	return outBytes, currentByte, nil
 }
+
+// Lays out the unsigned number in 'numStr' in the annotated 'length' according to the 'sign', 'forcesign' and 'padspace' annotations.
+// The padding always goes between the leading sign (or parenthesis) and the digits.
+//
+// Returns the formatted bytes or an error if the result doesn't fit.
+func formatSignedNumber(numStr string, isNegative bool, field *fieldPlan, length int) ([]byte, error) {
+
+	var prefix, suffix string
+	switch field.signConvention {
+	case signLeading:
+		if isNegative {
+			prefix = "-"
+		} else if field.isSignForced {
+			prefix = "+"
+		}
+	case signTrailing:
+		if isNegative {
+			suffix = "-"
+		} else if field.isSignForced {
+			suffix = "+"
+		}
+	case signNone:
+		if isNegative {
+			return []byte{}, ErrorNegativeUnsignedValue
+		}
+	case signParen:
+		if isNegative {
+			prefix, suffix = "(", ")"
+		}
+	}
+
+	var currLength = len(prefix) + len(numStr) + len(suffix)
+	if currLength > length {
+		return []byte{}, newInvalidValueLengthError(prefix+numStr+suffix, currLength)
+	}
+
+	var outBytes = []byte(prefix)
+	if currLength < length {
+		var paddingByte byte
+		if field.isPadSpace {
+			paddingByte = byte(' ')
+		} else {
+			paddingByte = byte('0')
+		}
+		outBytes, _ = appendPaddingBytes(outBytes, length-currLength, paddingByte)
+	}
+
+	outBytes = append(outBytes, numStr...)
+	outBytes = append(outBytes, suffix...)
+
+	return outBytes, nil
+}
+
+// Lays out the first annotated null sentinel in the annotated 'length' padded with spaces before the value like a string.
+//
+// Returns the formatted bytes or an error if there is no 'null' annotation or the sentinel doesn't fit.
+func marshalNullSentinel(field *fieldPlan, length int) ([]byte, error) {
+
+	if len(field.nullPatterns) == 0 {
+		return []byte{}, ErrorMissingNullAnnotation
+	}
+
+	var tempBytes = []byte(field.nullPatterns[0])
+	if len(tempBytes) > length {
+		return []byte{}, newInvalidValueLengthError(string(tempBytes), len(tempBytes))
+	}
+
+	var outBytes, _ = appendPaddingBytes([]byte{}, length-len(tempBytes), byte(' '))
+	return append(outBytes, tempBytes...), nil
+}
+
+// Writes the string or byte slice in 'recordField' preceded by its length.
+// The relative length of the address annotation is optional and limits the length of the value.
+func marshalLengthPrefixed(recordField reflect.Value, field *fieldPlan) ([]byte, error) {
+
+	var payload []byte
+	if recordField.Kind() == reflect.String {
+		payload = []byte(recordField.String())
+	} else {
+		payload = recordField.Bytes()
+	}
+
+	if field.relativeAnnotatedLength > 0 && len(payload) > field.relativeAnnotatedLength {
+		return []byte{}, newInvalidValueLengthError(string(payload), len(payload))
+	}
+
+	var outBytes, err = formatLengthPrefix(len(payload), field.lenPrefixWidth, field.isLenPrefixBinary)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return append(outBytes, payload...), nil
+}
+
+// Formats the 'length' as a prefix of 'width' bytes. It's either '0' padded ASCII digits or a big-endian binary number.
+// Gives an error if the length doesn't fit.
+func formatLengthPrefix(length int, width int, isBinary bool) ([]byte, error) {
+
+	if !isBinary {
+		var tempStr = strconv.Itoa(length)
+		if len(tempStr) > width {
+			return []byte{}, newInvalidValueLengthError(tempStr, len(tempStr))
+		}
+		var outBytes, _ = appendPaddingBytes([]byte{}, width-len(tempStr), byte('0'))
+		return append(outBytes, tempStr...), nil
+	}
+
+	var outBytes = make([]byte, width)
+	var remaining = length
+	for i := width - 1; i >= 0; i-- {
+		outBytes[i] = byte(remaining & 0xFF)
+		remaining >>= 8
+	}
+	if remaining != 0 {
+		return []byte{}, newInvalidValueLengthError(strconv.Itoa(length), width)
+	}
+
+	return outBytes, nil
+}
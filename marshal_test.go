@@ -456,7 +456,17 @@ type testDynamicArrayMarshalWrongType struct {
 
 type testDynamicArrayMarshalWrongValue struct {
 	IncorrectSize int   `bin:":2"`
-	TheArray1     []int `bin:"array:IncorrectSize,:1"`
+	TheArray1     []int `bin:"array:IncorrectSize,strictsize,:1"`
+}
+
+type testDynamicArrayMarshalDerived struct {
+	Count     int   `bin:":2"`
+	TheArray1 []int `bin:"array:Count,:1"`
+}
+
+type testDynamicArrayMarshalStrict struct {
+	Count     int   `bin:":1"`
+	TheArray1 []int `bin:"array:Count,strictsize,:1"`
 }
 
 type testDynamicArrayMarshal struct {
@@ -509,6 +519,36 @@ func TestMasrshalDynamicArray(t *testing.T) {
 
 	//-------------------------------------------------------------------------
 
+	var inputDataDerived = testDynamicArrayMarshalDerived{
+		Count:     0,
+		TheArray1: []int{0, 1, 2, 3},
+	}
+
+	result, err = Marshal(inputDataDerived, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("040123"), result)
+
+	//-------------------------------------------------------------------------
+
+	var inputDataStrict = testDynamicArrayMarshalStrict{
+		Count:     2,
+		TheArray1: []int{0, 1, 2},
+	}
+
+	result, err = Marshal(inputDataStrict, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	var errSizeMismatch *ErrorArraySizeMismatch
+	assert.Equal(t, true, errors.Is(err, errSizeMismatch))
+	assert.True(t, errors.As(err, &errSizeMismatch))
+	assert.Equal(t, 2, errSizeMismatch.Declared)
+	assert.Equal(t, 3, errSizeMismatch.Actual)
+
+	inputDataStrict.Count = 3
+	result, err = Marshal(inputDataStrict, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("3012"), result)
+
+	//-------------------------------------------------------------------------
+
 	var inputDataNoField = testDynamicArrayMarshalUnknownField{
 		NotHere:   "A",
 		TheArray1: []int{0, 1, 2},
@@ -518,3 +558,708 @@ func TestMasrshalDynamicArray(t *testing.T) {
 	_ = result
 	assert.Equal(t, true, errors.Is(err, ErrorUnknownFieldName))
 }
+
+//
+//-Blank Zero------------------------------------------------------------------
+
+type testBlankZeroMarshal struct {
+	IntField        int      `bin:":3,blankzero"`
+	FloatField      float32  `bin:":4,blankzero"`
+	NonZeroField    int      `bin:":3,blankzero"`
+	NilPointer      *int     `bin:":2"`
+	PointerField    *float64 `bin:":9,precision:2"`
+	ZeroWithoutNote int      `bin:":2"`
+}
+
+func TestMarshalBlankZero(t *testing.T) {
+
+	var pointerValue = 1.5
+	var inputData = testBlankZeroMarshal{
+		IntField:        0,
+		FloatField:      0,
+		NonZeroField:    7,
+		NilPointer:      nil,
+		PointerField:    &pointerValue,
+		ZeroWithoutNote: 0,
+	}
+
+	result, err := Marshal(inputData, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("       007  01.50E+0000"), result)
+}
+
+//
+//-Sign Conventions------------------------------------------------------------
+
+type testSignConventionMarshal struct {
+	Leading           int     `bin:":4,sign:leading"`
+	Trailing          int     `bin:":4,sign:trailing"`
+	TrailingForced    int     `bin:":4,sign:trailing,forcesign"`
+	TrailingPadspace  int     `bin:":4,sign:trailing,padspace"`
+	None              int     `bin:":4,sign:none"`
+	Paren             int     `bin:":5,sign:paren"`
+	ParenPositive     int     `bin:":5,sign:paren"`
+	TrailingFloat     float32 `bin:":6,sign:trailing,precision:2"`
+	ParenFloatPadding float32 `bin:":7,sign:paren,padspace,precision:1"`
+}
+
+type testSignConventionNoneMarshal struct {
+	None int `bin:":4,sign:none"`
+}
+
+type testSignConventionInvalidMarshal struct {
+	Invalid int `bin:":4,sign:middle"`
+}
+
+func TestMarshalSignConvention(t *testing.T) {
+
+	var inputData = testSignConventionMarshal{
+		Leading:           -64,
+		Trailing:          -64,
+		TrailingForced:    64,
+		TrailingPadspace:  -64,
+		None:              64,
+		Paren:             -64,
+		ParenPositive:     64,
+		TrailingFloat:     -6.4,
+		ParenFloatPadding: -6.4,
+	}
+
+	result, err := Marshal(inputData, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("-064064-064+ 64-0064(064)0006406.40-(  6.4)"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testSignConventionNoneMarshal{None: -1}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorNegativeUnsignedValue))
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testSignConventionInvalidMarshal{Invalid: 1}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidSign *ErrorInvalidSignConvention
+	assert.Equal(t, true, errors.Is(err, errInvalidSign))
+}
+
+//
+//-Null Sentinels--------------------------------------------------------------
+
+type testNullSentinelMarshal struct {
+	NilPointer   *float32 `bin:":5,null:*****"`
+	NaNFloat     float64  `bin:":5,null:ERR,null:-----"`
+	IsFlagged    bool
+	FlaggedInt   int `bin:":4,null:----,nullflag:IsFlagged"`
+	IsNotFlagged bool
+	RegularInt   int `bin:":4,null:----,nullflag:IsNotFlagged"`
+}
+
+type testNullSentinelUnknownFlagMarshal struct {
+	FlaggedInt int `bin:":4,null:----,nullflag:Unknown"`
+}
+
+func TestMarshalNullSentinel(t *testing.T) {
+
+	var inputData = testNullSentinelMarshal{
+		NilPointer:   nil,
+		NaNFloat:     math.NaN(),
+		IsFlagged:    true,
+		FlaggedInt:   0,
+		IsNotFlagged: false,
+		RegularInt:   12,
+	}
+
+	result, err := Marshal(inputData, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("*****  ERR----0012"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testNullSentinelUnknownFlagMarshal{}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorUnknownFieldName))
+}
+
+//
+//-Rounding--------------------------------------------------------------------
+
+type testRoundingMarshal struct {
+	HalfUp           float32 `bin:":4,precision:2,round:half-up"`
+	HalfEvenDown     float32 `bin:":4,precision:2,round:half-even"`
+	HalfEvenUp       float32 `bin:":4,precision:2,round:half-even"`
+	Down             float32 `bin:":4,precision:2,round:down"`
+	DownNegative     float32 `bin:":5,precision:2,round:down"`
+	HalfUpCarry      float64 `bin:":8,precision:2,round:half-up"`
+	ExactWithPadding float32 `bin:":4,precision:2,exact"`
+}
+
+type testRoundingExactMarshal struct {
+	Exact float32 `bin:":4,precision:1,exact"`
+}
+
+type testRoundingInvalidMarshal struct {
+	Invalid float32 `bin:":4,precision:1,round:up"`
+}
+
+func TestMarshalRounding(t *testing.T) {
+
+	var inputData = testRoundingMarshal{
+		HalfUp:           1.005,
+		HalfEvenDown:     1.125,
+		HalfEvenUp:       1.135,
+		Down:             1.239,
+		DownNegative:     -1.239,
+		HalfUpCarry:      9.995,
+		ExactWithPadding: 1.2,
+	}
+
+	result, err := Marshal(inputData, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("1.011.121.141.23-1.231.00E+011.20"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testRoundingExactMarshal{Exact: 1.25}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	var errPrecisionLoss *ErrorPrecisionLoss
+	assert.Equal(t, true, errors.Is(err, errPrecisionLoss))
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testRoundingInvalidMarshal{Invalid: 1.25}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidRoundingMode *ErrorInvalidRoundingMode
+	assert.Equal(t, true, errors.Is(err, errInvalidRoundingMode))
+}
+
+//
+//-Scaling---------------------------------------------------------------------
+
+type testScalingMarshal struct {
+	Hemoglobin  float32 `bin:":4,scale:0.1,precision:0"`
+	Hundredths  int     `bin:":4,scale:0.01"`
+	WithOffset  float64 `bin:":9,scale:2,offset:-40,precision:3"`
+	ScaledArray []int   `bin:"array:2,:3,scale:10"`
+}
+
+type testScalingNotIntegerMarshal struct {
+	Tenths int `bin:":4,scale:10"`
+}
+
+type testScalingBoundaryMarshal struct {
+	Halves int `bin:":20,scale:0.5"`
+}
+
+func TestMarshalScaling(t *testing.T) {
+
+	var inputData = testScalingMarshal{
+		Hemoglobin:  6.4,
+		Hundredths:  12,
+		WithOffset:  -19,
+		ScaledArray: []int{10, 250},
+	}
+
+	result, err := Marshal(inputData, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("006412001.050E+01001025"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testScalingNotIntegerMarshal{Tenths: 15}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	var errNotInteger *ErrorScaledValueNotInteger
+	assert.Equal(t, true, errors.Is(err, errNotInteger))
+
+	//-------------------------------------------------------------------------
+
+	// large integers keep all digits and 2^63 doesn't fit into an int anymore
+	result, err = Marshal(testScalingBoundaryMarshal{Halves: 1 << 61}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("04611686018427387904"), result)
+
+	_, err = Marshal(testScalingBoundaryMarshal{Halves: 1 << 62}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errNotInteger))
+
+	_, err = Marshal(testScalingBoundaryMarshal{Halves: math.MinInt64 / 2}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+}
+
+//
+//-Forward References and Fill Arrays------------------------------------------
+
+type testForwardReferenceMarshal struct {
+	Items   []int  `bin:"array:Count,:2"`
+	Trailer string `bin:":1"`
+	Count   int    `bin:":1"`
+}
+
+type testFillArrayMarshal struct {
+	Items   []testArrayInnerMarshal `bin:"array:fill"`
+	Trailer string                  `bin:":3"`
+}
+
+func TestMarshalForwardReferenceAndFillArray(t *testing.T) {
+
+	var inputForward = testForwardReferenceMarshal{
+		Items:   []int{11, 22},
+		Trailer: "X",
+		Count:   2,
+	}
+
+	result, err := Marshal(inputForward, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("1122X2"), result)
+
+	//-------------------------------------------------------------------------
+
+	var inputFill = testFillArrayMarshal{
+		Items: []testArrayInnerMarshal{
+			{InnerData1: 1, InnerData2: 2},
+			{InnerData1: 3, InnerData2: 4},
+		},
+		Trailer: "END",
+	}
+
+	result, err = Marshal(inputFill, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("1234END"), result)
+}
+
+//
+//-Length Prefix---------------------------------------------------------------
+
+type testLengthPrefixMarshal struct {
+	Comment string                  `bin:"lenprefix:3"`
+	Raw     []byte                  `bin:"lenprefix:2:binary"`
+	Items   []testArrayInnerMarshal `bin:"lenprefix:1"`
+	Ints    []int                   `bin:"lenprefix:2,:1"`
+	After   string                  `bin:"32:2"`
+}
+
+type testLengthPrefixTooLongMarshal struct {
+	Comment string `bin:":5,lenprefix:3"`
+}
+
+func TestMarshalLengthPrefix(t *testing.T) {
+
+	var inputData = testLengthPrefixMarshal{
+		Comment: "Hello World!",
+		Raw:     []byte("abc"),
+		Items: []testArrayInnerMarshal{
+			{InnerData1: 1, InnerData2: 2},
+			{InnerData1: 3, InnerData2: 4},
+		},
+		Ints:  []int{1, 2, 3},
+		After: "ZZ",
+	}
+
+	result, err := Marshal(inputData, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("012Hello World!\x00\x03abc2123403123xxZZ"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testLengthPrefixTooLongMarshal{Comment: "Hello World!"}, 'x', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidValueLength *ErrorInvalidValueLength
+	assert.Equal(t, true, errors.Is(err, errInvalidValueLength))
+}
+
+//
+//-Constants-------------------------------------------------------------------
+
+type testConstantMarshal struct {
+	_          string `bin:"const:D,:2"`
+	RecordType string `bin:"const:R"`
+	Value      int    `bin:":3"`
+	recordEnd  string `bin:"const:END"`
+}
+
+type testConstantTooLongMarshal struct {
+	RecordType string `bin:"const:DATA,:2"`
+}
+
+func TestMarshalConstant(t *testing.T) {
+
+	result, err := Marshal(testConstantMarshal{Value: 42}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("D R042END"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testConstantTooLongMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidValueLength *ErrorInvalidValueLength
+	assert.Equal(t, true, errors.Is(err, errInvalidValueLength))
+}
+
+//
+//-Conditional Fields----------------------------------------------------------
+
+type testConditionMarshal struct {
+	Diluted  string `bin:":1"`
+	Dilution int    `bin:":3,if:Diluted=Y"`
+	Comment  string `bin:":2,if:Diluted!=Y"`
+	Value    int    `bin:":2"`
+}
+
+type testConditionUnknownFieldMarshal struct {
+	Dilution int `bin:":3,if:Diluted=Y"`
+}
+
+type testConditionInvalidMarshal struct {
+	Diluted  string `bin:":1"`
+	Dilution int    `bin:":3,if:Diluted"`
+}
+
+func TestMarshalCondition(t *testing.T) {
+
+	result, err := Marshal(testConditionMarshal{Diluted: "Y", Dilution: 10, Comment: "AB", Value: 5}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("Y01005"), result)
+
+	result, err = Marshal(testConditionMarshal{Diluted: "N", Dilution: 10, Comment: "AB", Value: 5}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("NAB05"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testConditionUnknownFieldMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorUnknownFieldName))
+
+	_, err = Marshal(testConditionInvalidMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidCondition *ErrorInvalidCondition
+	assert.Equal(t, true, errors.Is(err, errInvalidCondition))
+}
+
+//
+//-Root Relative Positions-----------------------------------------------------
+
+type testRootPositionInnerMarshal struct {
+	Code  string `bin:":2"`
+	Gap   string `bin:"3:1"`
+	Value int    `bin:"@10:3"`
+}
+
+type testRootPositionMarshal struct {
+	RecordType string `bin:":2"`
+	Inner      testRootPositionInnerMarshal
+	Tail       string `bin:"@14:2"`
+}
+
+type testRootPositionBackwardsMarshal struct {
+	First  string `bin:":5"`
+	Second string `bin:"@3:2"`
+}
+
+func TestMarshalRootRelativePosition(t *testing.T) {
+
+	var inputData = testRootPositionMarshal{
+		RecordType: "D ",
+		Inner:      testRootPositionInnerMarshal{Code: "AB", Gap: "X", Value: 42},
+		Tail:       "ZZ",
+	}
+
+	result, err := Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+
+	// the relative position of 'Gap' is counted from the start of 'Inner'
+	assert.Equal(t, []byte("D AB X    042 ZZ"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testRootPositionBackwardsMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidOffset *ErrorInvalidOffset
+	assert.Equal(t, true, errors.Is(err, errInvalidOffset))
+}
+
+//
+//-Overlays--------------------------------------------------------------------
+
+type testOverlayMarshal struct {
+	Kind    string  `bin:":1"`
+	Raw     string  `bin:":6"`
+	Result  float32 `bin:":6,overlay:Raw,primary,if:Kind=R"`
+	Comment string  `bin:":4,overlay:Raw,primary,if:Kind=C"`
+	Flag    string  `bin:":1"`
+}
+
+type testOverlayAmbiguousMarshal struct {
+	Raw     string `bin:":4"`
+	Number  int    `bin:":4,overlay:Raw,primary"`
+	Comment string `bin:":4,overlay:Raw,primary"`
+}
+
+type testOverlayTooLongMarshal struct {
+	Raw     string `bin:":4"`
+	Comment string `bin:":5,overlay:Raw"`
+}
+
+func TestMarshalOverlay(t *testing.T) {
+
+	result, err := Marshal(testOverlayMarshal{Kind: "R", Raw: "ignore", Result: 1.5, Comment: "HEMO", Flag: "F"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("R0001.5F"), result)
+
+	result, err = Marshal(testOverlayMarshal{Kind: "C", Raw: "ignore", Result: 1.5, Comment: "HEMO", Flag: "F"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("CHEMO  F"), result)
+
+	// without a primary view the overlaid field is written
+	result, err = Marshal(testOverlayMarshal{Kind: "X", Raw: "RAWRAW", Result: 1.5, Comment: "HEMO", Flag: "F"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("XRAWRAWF"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testOverlayAmbiguousMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorAmbiguousOverlay))
+
+	_, err = Marshal(testOverlayTooLongMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorInvalidOverlay))
+}
+
+//
+//-Checksums-------------------------------------------------------------------
+
+type testChecksumMarshal struct {
+	Crc   string `bin:":4,checksum:crc16:5-14"`
+	Start string `bin:":1"`
+	Data  string `bin:":9"`
+	Sum   string `bin:":2,checksum:sum8:5-"`
+	Xor   int    `bin:":3,checksum:xor:5-14"`
+}
+
+type testChecksumInvalidMarshal struct {
+	Data string `bin:":9"`
+	Sum  string `bin:":2,checksum:sum8:5"`
+}
+
+func TestMarshalChecksum(t *testing.T) {
+
+	result, err := Marshal(testChecksumMarshal{Start: "\x02", Data: "123456789"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("BB3D\x02123456789DD049"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testChecksumInvalidMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidChecksum *ErrorInvalidChecksum
+	assert.Equal(t, true, errors.Is(err, errInvalidChecksum))
+}
+
+//
+//-Length Fields---------------------------------------------------------------
+
+type testLengthBlockMarshal struct {
+	Code  string `bin:":2"`
+	Items []int  `bin:"array:fill,:1"`
+}
+
+type testLengthMarshal struct {
+	RecordLength int `bin:":3,length:self"`
+	BlockLength  int `bin:":2,length:Block"`
+	Block        testLengthBlockMarshal
+	Comment      string `bin:":4"`
+}
+
+type testLengthUnknownFieldMarshal struct {
+	BlockLength int `bin:":2,length:Block"`
+}
+
+func TestMarshalLengthField(t *testing.T) {
+
+	var inputData = testLengthMarshal{
+		RecordLength: 99, // ignored
+		Block:        testLengthBlockMarshal{Code: "AB", Items: []int{1, 2, 3}},
+		Comment:      "DONE",
+	}
+
+	result, err := Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("01405AB123DONE"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testLengthUnknownFieldMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorUnknownFieldName))
+}
+
+//
+//-Separators------------------------------------------------------------------
+
+type testSeparatorMarshal struct {
+	Terminated []string `bin:"array:terminator,sep:|,:2"`
+	Fixed      []int    `bin:"array:3,sep:'\\\\',:1"`
+	Count      int      `bin:":1"`
+	Dynamic    []string `bin:"array:Count,sep:^,:1"`
+	Trailer    string   `bin:":1"`
+}
+
+type testSeparatorInvalidMarshal struct {
+	Value string `bin:":2,sep:|"`
+}
+
+func TestMarshalSeparator(t *testing.T) {
+
+	var inputData = testSeparatorMarshal{
+		Terminated: []string{"AA", "BB", "CC"},
+		Fixed:      []int{1, 2},
+		Dynamic:    []string{"X", "Y"},
+		Trailer:    "E",
+	}
+
+	// the missing element of the fixed size array is padded with zero bytes
+	result, err := Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("AA|BB|CC\r1\\2\\\x002X^YE"), result)
+
+	//-------------------------------------------------------------------------
+
+	// a single element has no separator
+	inputData = testSeparatorMarshal{
+		Terminated: []string{"AA"},
+		Fixed:      []int{1, 2, 3},
+		Dynamic:    []string{"X"},
+		Trailer:    "E",
+	}
+
+	result, err = Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("AA\r1\\2\\31XE"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testSeparatorInvalidMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorInvalidSeparator))
+}
+
+//
+//-Terminator Overrides--------------------------------------------------------
+
+type testTerminatorMarshal struct {
+	_        struct{}                      `bin:"terminator:'\\x03'"`
+	Header   string                        `bin:":2"`
+	Results  []testTerminatorResultMarshal `bin:"array:terminator,terminator:'\\x17'"`
+	Comments []string                      `bin:"array:terminator,:1"`
+}
+
+type testTerminatorResultMarshal struct {
+	Code  string `bin:":2"`
+	Value int    `bin:":1"`
+}
+
+type testTerminatorInvalidMarshal struct {
+	Values []string `bin:"array:terminator,terminator:'',:1"`
+}
+
+type testTerminatorMisplacedMarshal struct {
+	A string `bin:":1"`
+	B string `bin:":3,terminator:X"` // neither an array nor a marker for the struct
+}
+
+func TestMarshalTerminatorOverride(t *testing.T) {
+
+	var inputData = testTerminatorMarshal{
+		Header:   "H1",
+		Results:  []testTerminatorResultMarshal{{Code: "AB", Value: 1}, {Code: "CD", Value: 2}},
+		Comments: []string{"x", "y"},
+	}
+
+	// the call level terminator is only the fallback
+	result, err := Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("H1AB1CD2\x17xy\x03"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testTerminatorInvalidMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidTerminator *ErrorInvalidTerminator
+	assert.Equal(t, true, errors.Is(err, errInvalidTerminator))
+
+	_, err = Marshal(testTerminatorMisplacedMarshal{A: "A", B: "BBB"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorMisplacedStructAnnotation))
+}
+
+//
+//-Default Values--------------------------------------------------------------
+
+type testDefaultMarshal struct {
+	Version string   `bin:":2,default:V2"`
+	UnitNo  int      `bin:":2,default:7"`
+	Factor  *float32 `bin:":4,precision:2,default:1.5"`
+	Filler  string   `bin:":3,default:'X Y'"`
+	Comment string   `bin:":2"`
+}
+
+type testDefaultInvalidMarshal struct {
+	UnitNo int `bin:":2,default:seven"`
+}
+
+func TestMarshalDefault(t *testing.T) {
+
+	// the zero fields and the nil pointer get their default
+	result, err := Marshal(testDefaultMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("V2071.50X Y  "), result)
+
+	//-------------------------------------------------------------------------
+
+	var factor float32 = 2.25
+	var inputData = testDefaultMarshal{
+		Version: "V3",
+		UnitNo:  12,
+		Factor:  &factor,
+		Filler:  "ABC",
+		Comment: "OK",
+	}
+
+	result, err = Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("V3122.25ABCOK"), result)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testDefaultInvalidMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidDefault *ErrorInvalidDefault
+	assert.Equal(t, true, errors.Is(err, errInvalidDefault))
+}
+
+//
+//-Tag Keys--------------------------------------------------------------------
+
+type testTagKeyMarshal struct {
+	RecordType string `bin:":1"`
+	SampleId   string `bin:":4" bin_v2:":6"`
+	Value      int    `bin:":3" bin_v2:":4"`
+	Filler     string `bin:":2" bin_v2:""` // only in the first layout
+	Flag       string `bin_v2:":1"`        // only in the second layout
+	Nested     testTagKeyInnerMarshal
+}
+
+type testTagKeyInnerMarshal struct {
+	Code string `bin:":2" bin_v2:":3"`
+}
+
+func TestMarshalWithTagKey(t *testing.T) {
+
+	var inputData = testTagKeyMarshal{
+		RecordType: "D",
+		SampleId:   "S1",
+		Value:      42,
+		Filler:     "XX",
+		Flag:       "H",
+		Nested:     testTagKeyInnerMarshal{Code: "AB"},
+	}
+
+	result, err := Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("D  S1042XXAB"), result)
+
+	// the fields without a 'bin_v2' tag fall back to their 'bin' tag
+	result, err = MarshalWithTagKey(inputData, "bin_v2", ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("D    S10042H AB"), result)
+}
@@ -0,0 +1,161 @@
+package binfile
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Formats the float 'value' like strconv.FormatFloat with the 'f' or 'E' format, but rounds the decimal
+// representation of the value to 'precision' digits with the provided rounding mode instead of the binary one.
+// If 'isExact' is true, it gives an error instead of rounding when digits would be lost.
+//
+// NOTE: A negative precision means all digits needed and never rounds.
+func formatRoundedFloat(value float64, format byte, precision int, bitSize int, roundingMode string, isExact bool) (string, error) {
+
+	var shortest = strconv.FormatFloat(value, format, -1, bitSize)
+	if precision < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return shortest, nil
+	}
+
+	var sign = ""
+	if strings.HasPrefix(shortest, "-") {
+		sign = "-"
+		shortest = shortest[1:]
+	}
+
+	var mantissa = shortest
+	var exponent = 0
+	var hasExponent = false
+	if idx := strings.IndexByte(shortest, 'E'); idx >= 0 {
+		mantissa = shortest[:idx]
+		exponent, _ = strconv.Atoi(shortest[idx+1:])
+		hasExponent = true
+	}
+
+	var intPart, fracPart = mantissa, ""
+	if idx := strings.IndexByte(mantissa, '.'); idx >= 0 {
+		intPart, fracPart = mantissa[:idx], mantissa[idx+1:]
+	}
+
+	if len(fracPart) <= precision {
+		fracPart += strings.Repeat("0", precision-len(fracPart))
+		return sign + joinFloatParts(intPart, fracPart, exponent, hasExponent), nil
+	}
+
+	if isExact {
+		return "", newPrecisionLossError(sign+shortest, precision)
+	}
+
+	var digits = intPart + fracPart[:precision]
+	var rest = fracPart[precision:]
+	if isRoundingUp(digits[len(digits)-1], rest, roundingMode) {
+		digits = incrementDecimalDigits(digits)
+	}
+
+	// the carry added a new digit in front like 9.99 -> 10.0
+	var intLength = len(intPart) + len(digits) - len(intPart+fracPart[:precision])
+	intPart, fracPart = digits[:intLength], digits[intLength:]
+	if hasExponent && len(intPart) > 1 { // keep the scientific notation normalized
+		fracPart = (intPart[1:] + fracPart)[:precision]
+		intPart = intPart[:1]
+		exponent++
+	}
+
+	return sign + joinFloatParts(intPart, fracPart, exponent, hasExponent), nil
+}
+
+// Decides if the kept digits need to be rounded up according to the 'roundingMode' by looking
+// at the 'lastKept' digit and the dropped digits in 'rest'.
+func isRoundingUp(lastKept byte, rest string, roundingMode string) bool {
+
+	switch roundingMode {
+	case roundHalfUp:
+		return rest[0] >= '5'
+	case roundHalfEven:
+		if rest[0] != '5' {
+			return rest[0] > '5'
+		}
+		if strings.TrimRight(rest[1:], "0") != "" { // more than half
+			return true
+		}
+		return (lastKept-'0')%2 == 1
+	}
+
+	return false // roundDown
+}
+
+// Adds one to the last digit of the decimal digit string carrying over as needed.
+func incrementDecimalDigits(digits string) string {
+
+	var bytes = []byte(digits)
+	for i := len(bytes) - 1; i >= 0; i-- {
+		if bytes[i] < '9' {
+			bytes[i]++
+			return string(bytes)
+		}
+		bytes[i] = '0'
+	}
+
+	return "1" + string(bytes)
+}
+
+// Puts the integer and fractional digits together with the exponent in the format of strconv.FormatFloat.
+func joinFloatParts(intPart string, fracPart string, exponent int, hasExponent bool) string {
+
+	var str = intPart
+	if len(fracPart) > 0 {
+		str += "." + fracPart
+	}
+
+	if hasExponent {
+		var expSign = "+"
+		if exponent < 0 {
+			expSign = "-"
+			exponent = -exponent
+		}
+		var expStr = strconv.Itoa(exponent)
+		if len(expStr) < 2 {
+			expStr = "0" + expStr
+		}
+		str += "E" + expSign + expStr
+	}
+
+	return str
+}
+
+// Converts a value read from the byte array into the unit of the field. (value * scale + offset)
+func scaleToField(value float64, scale float64, offset float64) float64 {
+	return roundToSignificantDigits(value*scale + offset)
+}
+
+// Converts a field's value into the unit written to the byte array. ((value - offset) / scale)
+func scaleToWire(value float64, scale float64, offset float64) float64 {
+	return roundToSignificantDigits((value - offset) / scale)
+}
+
+// Removes the binary noise of the scaling arithmetics like 6.4 / 0.1 = 63.99999999999999
+// by rounding to the 15 significant digits a float64 can reliably represent.
+//
+// NOTE: A scaled value with more than 15 significant digits loses the digits after them.
+// Integral values are kept as they are, so large integers aren't changed.
+func roundToSignificantDigits(value float64) float64 {
+	if value == math.Trunc(value) || math.IsInf(value, 0) {
+		return value
+	}
+	var rounded, err = strconv.ParseFloat(strconv.FormatFloat(value, 'g', 15, 64), 64)
+	if err != nil {
+		return value
+	}
+	return rounded
+}
+
+// Converts a scaled value back into an integer.
+// Gives an error if the value has a fractional part or doesn't fit into an int.
+func scaledValueToInt(value float64) (int, error) {
+	// 2^63 is the first float above the int64 range, math.MaxInt itself isn't exactly representable
+	if value != math.Trunc(value) || value >= float64(math.MaxInt) || value < float64(math.MinInt) {
+		return 0, newScaledValueNotIntegerError(value)
+	}
+	return int(value), nil
+}
@@ -0,0 +1,175 @@
+package binfile
+
+import (
+	"math"
+	"reflect"
+)
+
+// A Record holds the values of a message decoded with a Schema, keyed by the field names in the order of the schema.
+//
+// The values are typed like the fields: string, int, float32 or float64, nil for a missing pointer value,
+// *Record for a struct and a slice of the element type (e.g. []int or []*Record) for an array.
+type Record struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// Returns an empty Record.
+func NewRecord() *Record {
+	return &Record{values: map[string]interface{}{}}
+}
+
+// Sets the value of the key. A new key is added after the existing ones.
+func (r *Record) Set(key string, value interface{}) *Record {
+	if _, isFound := r.values[key]; !isFound {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = value
+	return r
+}
+
+// Returns the value of the key and a bool which is true if found. (', ok' idiom)
+func (r *Record) Get(key string) (interface{}, bool) {
+	var value, isFound = r.values[key]
+	return value, isFound
+}
+
+// Returns the keys in order.
+func (r *Record) Keys() []string {
+	return append([]string{}, r.keys...)
+}
+
+// Returns the number of keys.
+func (r *Record) Len() int {
+	return len(r.keys)
+}
+
+// Converts a struct built from a Schema to a Record.
+func structToRecord(structValue reflect.Value) *Record {
+
+	var record = NewRecord()
+	for fieldNo := 0; fieldNo < structValue.NumField(); fieldNo++ {
+		var name = structValue.Type().Field(fieldNo).Name
+		if name == schemaAnnotationsFieldName {
+			continue
+		}
+		record.Set(name, valueToRecordValue(structValue.Field(fieldNo)))
+	}
+
+	return record
+}
+
+// Converts the value of a struct field to the value of a Record.
+func valueToRecordValue(fieldValue reflect.Value) interface{} {
+
+	switch fieldValue.Kind() {
+	case reflect.Ptr:
+		if fieldValue.IsNil() {
+			return nil
+		}
+		return valueToRecordValue(fieldValue.Elem())
+
+	case reflect.Struct:
+		return structToRecord(fieldValue)
+
+	case reflect.Slice:
+		if fieldValue.Type().Elem().Kind() != reflect.Struct {
+			return fieldValue.Interface()
+		}
+		var records = make([]*Record, fieldValue.Len())
+		for i := range records {
+			records[i] = structToRecord(fieldValue.Index(i))
+		}
+		return records
+	}
+
+	return fieldValue.Interface()
+}
+
+// Sets the fields of a struct built from a Schema to the values of the Record. The missing keys keep the zero value.
+//
+// Returns an error if the Record has a key without a field or a value which doesn't fit the type of its field.
+func recordToStruct(record *Record, structValue reflect.Value) error {
+
+	for _, key := range record.keys {
+
+		var fieldValue = structValue.FieldByName(key)
+		if !fieldValue.IsValid() || key == schemaAnnotationsFieldName {
+			return newProcessingFieldError(key, "", ErrorUnknownFieldName)
+		}
+
+		if err := setRecordValue(fieldValue, record.values[key]); err != nil {
+			return newProcessingFieldError(key, "", err)
+		}
+	}
+
+	return nil
+}
+
+// Sets the struct field to the value of a Record. A numeric value is converted to a numeric field,
+// but only if it keeps its value in an integer field.
+func setRecordValue(fieldValue reflect.Value, value interface{}) error {
+
+	if value == nil {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Ptr:
+		var elemValue = reflect.New(fieldValue.Type().Elem())
+		if err := setRecordValue(elemValue.Elem(), value); err != nil {
+			return err
+		}
+		fieldValue.Set(elemValue)
+		return nil
+
+	case reflect.Struct:
+		if record, isRecord := value.(*Record); isRecord {
+			return recordToStruct(record, fieldValue)
+		}
+
+	case reflect.Slice:
+		if records, isRecords := value.([]*Record); isRecords && fieldValue.Type().Elem().Kind() == reflect.Struct {
+			var sliceValue = reflect.MakeSlice(fieldValue.Type(), len(records), len(records))
+			for i, record := range records {
+				if err := recordToStruct(record, sliceValue.Index(i)); err != nil {
+					return err
+				}
+			}
+			fieldValue.Set(sliceValue)
+			return nil
+		}
+	}
+
+	var recordValue = reflect.ValueOf(value)
+	if recordValue.Type().AssignableTo(fieldValue.Type()) {
+		fieldValue.Set(recordValue)
+		return nil
+	}
+	if isNumericKind(fieldValue.Kind()) && (recordValue.CanInt() || recordValue.CanUint() || recordValue.CanFloat()) {
+		if fieldValue.Kind() == reflect.Int && !isIntegerValueOf(recordValue, fieldValue) {
+			return newInvalidRecordValueError(value, fieldValue.Type())
+		}
+		fieldValue.Set(recordValue.Convert(fieldValue.Type()))
+		return nil
+	}
+
+	return newUnsupportedTypeError(recordValue.Type())
+}
+
+// Checks if the numeric value has no fractional part and is in the range of the int field and returns a bool accordingly.
+func isIntegerValueOf(recordValue reflect.Value, fieldValue reflect.Value) bool {
+
+	switch {
+	case recordValue.CanInt():
+		return !fieldValue.OverflowInt(recordValue.Int())
+	case recordValue.CanUint():
+		return recordValue.Uint() <= math.MaxInt64 && !fieldValue.OverflowInt(int64(recordValue.Uint()))
+	}
+
+	// 2^63 is the first float above the int64 range, math.MaxInt64 itself isn't exactly representable
+	var floatValue = recordValue.Float()
+	return floatValue == math.Trunc(floatValue) && floatValue >= math.MinInt64 && floatValue < math.MaxInt64 &&
+		!fieldValue.OverflowInt(int64(floatValue))
+}
@@ -11,3 +11,34 @@ func getFieldFromStruct(structValue reflect.Value, name string) (reflect.Value,
 	}
 	return reflect.Value{}, false
 }
+
+// Iterates through the fields of the struct type in 'structType' and returns the type of the field with the provided 'name'
+// and a bool accordingly. (', ok' idiom)
+func getFieldTypeFromStructType(structType reflect.Type, name string) (reflect.Type, bool) {
+	for fieldNo := 0; fieldNo < structType.NumField(); fieldNo++ {
+		if structType.Field(fieldNo).Name == name {
+			return structType.Field(fieldNo).Type, true
+		}
+	}
+	return nil, false
+}
+
+// Checks if the type is one of the supported primitive types or a pointer to them and returns a bool accordingly.
+func isSupportedSimpleType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.String || isNumericKind(fieldType.Kind())
+}
+
+// Checks if the type is a byte slice, which is handled as a single value instead of an array, and returns a bool accordingly.
+func isByteSliceType(fieldType reflect.Type) bool {
+	return fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Uint8
+}
+
+// Sets the field to the zero value of its type (nil for pointers) if it's writable.
+func setZeroValue(fieldValue reflect.Value) {
+	if fieldValue.CanSet() {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+	}
+}
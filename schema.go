@@ -0,0 +1,236 @@
+package binfile
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Schema describes the fields of a message without a Go struct, e.g. loaded from a JSON or YAML file.
+// The fields have the same semantics as the annotations of the 'bin' tag.
+type Schema struct {
+	// Annotations for the whole message, like 'allowshort' or 'terminator:<string>'.
+	Annotations []string      `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Fields      []SchemaField `json:"fields" yaml:"fields"`
+}
+
+// A SchemaField describes a field of a Schema. The name must be an exported Go identifier, as the other fields
+// refer to it like to the fields of a struct (e.g. 'array:<name>').
+type SchemaField struct {
+	Name string `json:"name" yaml:"name"`
+	// One of "string", "int", "float32", "float64" or "struct", optionally with a "*" prefix for a pointer
+	// or a "[]" prefix for an array.
+	Type string `json:"type" yaml:"type"`
+	// The absolute position of the field in the struct. It's relative to the current position if not set.
+	Position *int `json:"position,omitempty" yaml:"position,omitempty"`
+	// The root relative position of the field, like the '@' address form.
+	RootPosition *int `json:"rootPosition,omitempty" yaml:"rootPosition,omitempty"`
+	Length       int  `json:"length,omitempty" yaml:"length,omitempty"`
+	// The mode of an array: "terminator", "fill", the number of elements or the name of the size field.
+	Array string `json:"array,omitempty" yaml:"array,omitempty"`
+	// Any other annotation, e.g. "trim", "precision:2" or "const:D ". The values don't need quoting.
+	Annotations []string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// The fields of a struct or of the elements of a struct array.
+	Fields []SchemaField `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// The name of the field holding the annotations of a whole schema struct. It's not part of a Record.
+const schemaAnnotationsFieldName = "BinfileSchemaAnnotations"
+
+// The Go types of the primitive schema field types.
+var schemaSimpleTypes = map[string]reflect.Type{
+	"string":  reflect.TypeOf(""),
+	"int":     reflect.TypeOf(0),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+}
+
+// Parses a Schema from JSON.
+func ParseSchemaJSON(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// Parses a Schema from YAML.
+func ParseSchemaYAML(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// A SchemaCodec converts the messages described by a Schema to and from a Record.
+// The annotations are processed and validated once on creation. It's safe for concurrent use.
+type SchemaCodec struct {
+	codec *Codec
+}
+
+// Builds the struct type of the schema with the fields annotated by 'bin' tags and returns a SchemaCodec for it
+// or an error if the schema or an annotation is invalid.
+func NewSchemaCodec(schema *Schema) (*SchemaCodec, error) {
+
+	var recordType, err = buildSchemaType(schema.Annotations, schema.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var codec *Codec
+	if codec, err = NewCodec(recordType); err != nil {
+		return nil, err
+	}
+
+	return &SchemaCodec{codec: codec}, nil
+}
+
+// Decodes a message from the byte array. See Unmarshal.
+//
+// Returns the decoded Record, the position after the message and an error if the message couldn't be decoded.
+func (c *SchemaCodec) Unmarshal(inputBytes []byte, enc Encoding, tz Timezone, arrayTerminator string) (*Record, int, error) {
+
+	var outputTarget = reflect.New(c.codec.recordType)
+	var position, err = c.codec.Unmarshal(inputBytes, outputTarget.Interface(), enc, tz, arrayTerminator)
+	if err != nil {
+		return nil, position, err
+	}
+
+	return structToRecord(outputTarget.Elem()), position, nil
+}
+
+// Encodes the Record to a byte array. See Marshal. The missing fields of the record get the zero value.
+//
+// Returns an error if a value of the record doesn't fit the type of its field or the message couldn't be encoded.
+func (c *SchemaCodec) Marshal(record *Record, padding byte, enc Encoding, tz Timezone, arrayTerminator string) ([]byte, error) {
+
+	var inputTarget = reflect.New(c.codec.recordType).Elem()
+	if err := recordToStruct(record, inputTarget); err != nil {
+		return []byte{}, err
+	}
+
+	return c.codec.Marshal(inputTarget.Interface(), padding, enc, tz, arrayTerminator)
+}
+
+// Builds a struct type with a field for every schema field, annotated like a Go struct would be.
+func buildSchemaType(annotations []string, fields []SchemaField) (reflect.Type, error) {
+
+	var structFields []reflect.StructField
+	if len(annotations) > 0 {
+		structFields = append(structFields, reflect.StructField{
+			Name: schemaAnnotationsFieldName,
+			Type: reflect.TypeOf(struct{}{}),
+			Tag:  reflect.StructTag(`bin:` + strconv.Quote(formatSchemaTag(annotations))),
+		})
+	}
+
+	var names = map[string]bool{}
+	for _, field := range fields {
+
+		if !isExportedIdentifier(field.Name) || field.Name == schemaAnnotationsFieldName || names[field.Name] {
+			return nil, newInvalidSchemaError(field.Name, "the name must be a unique exported Go identifier")
+		}
+		names[field.Name] = true
+
+		var fieldType, err = buildSchemaFieldType(field)
+		if err != nil {
+			return nil, err
+		}
+
+		var structField = reflect.StructField{Name: field.Name, Type: fieldType}
+		if fieldAnnotations := schemaFieldAnnotations(field); len(fieldAnnotations) > 0 { // nested structs are usually not annotated
+			structField.Tag = reflect.StructTag(`bin:` + strconv.Quote(formatSchemaTag(fieldAnnotations)))
+		}
+		structFields = append(structFields, structField)
+	}
+
+	return reflect.StructOf(structFields), nil
+}
+
+// Returns the Go type of the schema field.
+func buildSchemaFieldType(field SchemaField) (reflect.Type, error) {
+
+	var typeName = field.Type
+	var isSlice, isPtr = strings.HasPrefix(typeName, "[]"), false
+	typeName = strings.TrimPrefix(typeName, "[]")
+	if strings.HasPrefix(typeName, "*") {
+		typeName, isPtr = typeName[1:], true
+	}
+
+	var fieldType reflect.Type
+	if typeName == "struct" {
+		var err error
+		if fieldType, err = buildSchemaType(nil, field.Fields); err != nil {
+			return nil, err
+		}
+	} else if simpleType, isSimple := schemaSimpleTypes[typeName]; isSimple {
+		fieldType = simpleType
+	} else {
+		return nil, newInvalidSchemaError(field.Name, "unknown type '"+field.Type+"'")
+	}
+
+	if isPtr {
+		fieldType = reflect.PtrTo(fieldType)
+	}
+	if isSlice {
+		fieldType = reflect.SliceOf(fieldType)
+	}
+
+	return fieldType, nil
+}
+
+// Returns the annotations of the schema field in the order of a handwritten tag: the address, the array mode and the others.
+func schemaFieldAnnotations(field SchemaField) []string {
+
+	var annotations []string
+	if field.RootPosition != nil {
+		annotations = append(annotations, "@"+strconv.Itoa(*field.RootPosition)+":"+strconv.Itoa(field.Length))
+	} else if field.Position != nil {
+		annotations = append(annotations, strconv.Itoa(*field.Position)+":"+strconv.Itoa(field.Length))
+	} else if field.Length > 0 {
+		annotations = append(annotations, ":"+strconv.Itoa(field.Length))
+	}
+	if field.Array != "" {
+		annotations = append(annotations, "array:"+field.Array)
+	}
+
+	return append(annotations, field.Annotations...)
+}
+
+// Joins the annotations to a tag. A value containing characters of the tag grammar is quoted, see parseTag.
+func formatSchemaTag(annotations []string) string {
+
+	var quotedAnnotations = make([]string, len(annotations))
+	for i, annotation := range annotations {
+		quotedAnnotations[i] = annotation
+		if !strings.ContainsAny(annotation, " \t,'\"\\") && strconv.CanBackquote(annotation) {
+			continue
+		}
+		if idx := strings.Index(annotation, ":"); idx > 0 {
+			quotedAnnotations[i] = annotation[:idx+1] + strconv.Quote(annotation[idx+1:])
+		} else {
+			quotedAnnotations[i] = strconv.Quote(annotation)
+		}
+	}
+
+	return strings.Join(quotedAnnotations, ",")
+}
+
+// Checks if the name is an identifier starting with an upper case letter and returns a bool accordingly.
+func isExportedIdentifier(name string) bool {
+
+	if name == "" || name[0] < 'A' || name[0] > 'Z' {
+		return false
+	}
+	for _, char := range name {
+		if !(char == '_' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,157 @@
+package binfile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+//-Schema----------------------------------------------------------------------
+
+const testSchemaJSON = `{
+	"annotations": ["allowshort"],
+	"fields": [
+		{"name": "RecordType", "type": "string", "annotations": ["const:D "]},
+		{"name": "SampleId", "type": "string", "length": 6, "annotations": ["trim"]},
+		{"name": "Dilution", "type": "*float32", "length": 4, "annotations": ["precision:1", "null:    "]},
+		{"name": "Count", "type": "int", "length": 1},
+		{"name": "Results", "type": "[]struct", "array": "Count", "fields": [
+			{"name": "Code", "type": "string", "length": 2},
+			{"name": "Value", "type": "int", "length": 3}
+		]},
+		{"name": "Flags", "type": "[]string", "position": 24, "length": 1, "array": "terminator", "annotations": ["sep:,"]}
+	]
+}`
+
+const testSchemaYAML = `
+fields:
+  - name: RecordType
+    type: string
+    annotations: ["const:D "]
+  - name: Unit
+    type: int
+    rootPosition: 4
+    length: 2
+`
+
+// the struct equivalent to the JSON schema
+type testSchemaStruct struct {
+	_          struct{}                 `bin:"allowshort"`
+	RecordType string                   `bin:"const:'D '"`
+	SampleId   string                   `bin:":6,trim"`
+	Dilution   *float32                 `bin:":4,precision:1,null:'    '"`
+	Count      int                      `bin:":1"`
+	Results    []testSchemaResultStruct `bin:"array:Count"`
+	Flags      []string                 `bin:"24:1,array:terminator,sep:','"`
+}
+
+type testSchemaResultStruct struct {
+	Code  string `bin:":2"`
+	Value int    `bin:":3"`
+}
+
+func TestSchemaCodec(t *testing.T) {
+
+	schema, err := ParseSchemaJSON([]byte(testSchemaJSON))
+	assert.Nil(t, err)
+
+	codec, err := NewSchemaCodec(schema)
+	assert.Nil(t, err)
+
+	var inputBytes = []byte("D     S102.52AB001CD042 A,B\r")
+	record, position, err := codec.Unmarshal(inputBytes, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 28, position)
+	assert.Equal(t, []string{"RecordType", "SampleId", "Dilution", "Count", "Results", "Flags"}, record.Keys())
+
+	sampleId, _ := record.Get("SampleId")
+	assert.Equal(t, "S1", sampleId)
+	dilution, _ := record.Get("Dilution")
+	assert.Equal(t, float32(2.5), dilution)
+	results, _ := record.Get("Results")
+	assert.Equal(t, 2, len(results.([]*Record)))
+	value, _ := results.([]*Record)[1].Get("Value")
+	assert.Equal(t, 42, value)
+	flags, _ := record.Get("Flags")
+	assert.Equal(t, []string{"A", "B"}, flags)
+
+	// the schema has the same semantics as the tags
+	var structResult testSchemaStruct
+	_, err = Unmarshal(inputBytes, &structResult, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, "S1", structResult.SampleId)
+	assert.Equal(t, []string{"A", "B"}, structResult.Flags)
+
+	//-------------------------------------------------------------------------
+
+	result, err := codec.Marshal(record, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, inputBytes, result)
+
+	structBytes, err := Marshal(structResult, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, structBytes, result)
+
+	//-------------------------------------------------------------------------
+
+	// the missing keys get the zero value, numbers are converted to the type of the field
+	var newRecord = NewRecord().
+		Set("SampleId", "S2").
+		Set("Results", []*Record{NewRecord().Set("Code", "EF").Set("Value", 7.0)}).
+		Set("Flags", []string{"C"})
+
+	result, err = codec.Marshal(newRecord, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("D     S2    1EF007      C\r"), result)
+
+	_, err = codec.Marshal(NewRecord().Set("SampleId", 12), ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errUnsupportedType *ErrorUnsupportedType
+	assert.Equal(t, true, errors.Is(err, errUnsupportedType))
+
+	_, err = codec.Marshal(NewRecord().Set("Unknown", "X"), ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorUnknownFieldName))
+
+	//-------------------------------------------------------------------------
+
+	// a number is not truncated or wrapped around to fit into an int field
+	var errInvalidRecordValue *ErrorInvalidRecordValue
+	_, err = codec.Marshal(NewRecord().Set("Count", 1.9), ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errInvalidRecordValue))
+
+	_, err = codec.Marshal(NewRecord().Set("Count", 1e19), ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errInvalidRecordValue))
+
+	_, err = codec.Marshal(NewRecord().Set("Count", uint64(1<<63)), ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errInvalidRecordValue))
+}
+
+func TestSchemaYAML(t *testing.T) {
+
+	schema, err := ParseSchemaYAML([]byte(testSchemaYAML))
+	assert.Nil(t, err)
+
+	codec, err := NewSchemaCodec(schema)
+	assert.Nil(t, err)
+
+	record, _, err := codec.Unmarshal([]byte("D   07"), EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	unit, _ := record.Get("Unit")
+	assert.Equal(t, 7, unit)
+}
+
+func TestSchemaInvalid(t *testing.T) {
+
+	var errInvalidSchema *ErrorInvalidSchema
+
+	_, err := NewSchemaCodec(&Schema{Fields: []SchemaField{{Name: "sampleId", Type: "string", Length: 2}}})
+	assert.Equal(t, true, errors.Is(err, errInvalidSchema))
+
+	_, err = NewSchemaCodec(&Schema{Fields: []SchemaField{{Name: "Value", Type: "uint", Length: 2}}})
+	assert.Equal(t, true, errors.Is(err, errInvalidSchema))
+
+	// the annotations are checked like the tags
+	_, err = NewSchemaCodec(&Schema{Fields: []SchemaField{{Name: "Values", Type: "[]int", Length: 2}}})
+	assert.Equal(t, true, errors.Is(err, ErrorMissingArrayAnnotation))
+}
@@ -0,0 +1,87 @@
+package binfile
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parses the 'bin' tag into its annotations. The grammar is:
+//
+//	tag        = annotation { "," annotation }
+//	annotation = { char | quoted }
+//	quoted     = "'" { char | escape } "'" | `"` { char | escape } `"`
+//
+// Whitespace outside of quotes is ignored, so the annotations can be indented. A quoted part keeps its spaces
+// and commas and can contain Go-style escapes like "\r", "\x17" or "\u00e4". A backslash outside of quotes is an
+// error, so an unquoted escape like "terminator:\x03" isn't taken literally by mistake. An annotation in the form
+// of "<name>=<value>" is the same as "<name>:<value>" for the annotations which have a value. Empty annotations are removed.
+//
+// Returns the annotations with the quotes and escapes resolved or an error with the column (starting at 1) of the problem.
+func parseTag(tag string) ([]string, error) {
+
+	var annotations []string
+	var current strings.Builder
+	var hasQuoted = false
+
+	var flush = func() {
+		if current.Len() > 0 || hasQuoted {
+			annotations = append(annotations, normalizeKeyValue(current.String()))
+		}
+		current.Reset()
+		hasQuoted = false
+	}
+
+	for pos := 0; pos < len(tag); pos++ {
+
+		var char = tag[pos]
+		switch {
+		case char == ',':
+			flush()
+
+		case char == '\'' || char == '"':
+			var quoteStart = pos
+			pos++
+			for {
+				if pos >= len(tag) {
+					return nil, newInvalidTagError(quoteStart+1, "unterminated quote")
+				}
+				if tag[pos] == char {
+					break
+				}
+				var value, isMultibyte, tail, err = strconv.UnquoteChar(tag[pos:], char)
+				if err != nil {
+					return nil, newInvalidTagError(pos+1, "invalid escape sequence")
+				}
+				if value < utf8.RuneSelf || !isMultibyte { // a "\x" escape is a single byte
+					current.WriteByte(byte(value))
+				} else {
+					current.WriteRune(value)
+				}
+				pos = len(tag) - len(tail)
+			}
+			hasQuoted = true
+
+		case char == ' ' || char == '\t':
+			// indentation outside of quotes is ignored
+
+		case char == '\\':
+			return nil, newInvalidTagError(pos+1, "escape outside of quotes")
+
+		default:
+			current.WriteByte(char)
+		}
+	}
+	flush()
+
+	return annotations, nil
+}
+
+// Turns an annotation in the form of "<name>=<value>" into "<name>:<value>" if the name is one of the value annotations.
+func normalizeKeyValue(annotation string) string {
+	var idx = strings.IndexAny(annotation, ":=")
+	if idx > 0 && annotation[idx] == '=' && sliceContainsString(valueAnnotations, annotation[:idx]) {
+		return annotation[:idx] + ":" + annotation[idx+1:]
+	}
+	return annotation
+}
@@ -0,0 +1,86 @@
+package binfile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+//-Tag Grammar-----------------------------------------------------------------
+
+type testTagQuotedMarshal struct {
+	RecordType string   `bin:"const:'D ',:2"`
+	Values     []string `bin:"array:terminator, sep:',', terminator:'\\x17', :2"`
+	Comment    string   `bin:":3, null=\"N A\""`
+}
+
+type testTagInvalidMarshal struct {
+	Valid   string `bin:":2"`
+	Invalid string `bin:":2,const:'A"`
+}
+
+func TestParseTag(t *testing.T) {
+
+	annotations, err := parseTag(" :2 , trim,padspace ,,")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{":2", "trim", "padspace"}, annotations)
+
+	// quoted values keep spaces and commas and resolve escapes
+	annotations, err = parseTag(`const:'D ',sep:",",terminator:'\r\n',null:'\x17ä'`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"const:D ", "sep:,", "terminator:\r\n", "null:\x17ä"}, annotations)
+
+	// a quoted backslash
+	annotations, err = parseTag(`sep:'\\',:1`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`sep:\`, ":1"}, annotations)
+
+	// the key=value form of value annotations, a condition keeps its '='
+	annotations, err = parseTag(`const='12:30',if:Type='A B',trim=x`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"const:12:30", "if:Type=A B", "trim=x"}, annotations)
+
+	//-------------------------------------------------------------------------
+
+	_, err = parseTag(`:2,const:'AB`)
+	var errInvalidTag *ErrorInvalidTag
+	assert.Equal(t, true, errors.As(err, &errInvalidTag))
+	assert.Equal(t, 10, errInvalidTag.Column)
+
+	_, err = parseTag(`:2,const:'A\qB'`)
+	assert.Equal(t, true, errors.As(err, &errInvalidTag))
+	assert.Equal(t, 12, errInvalidTag.Column)
+
+	// an escape outside of quotes isn't taken literally
+	_, err = parseTag(`terminator:\x03`)
+	assert.Equal(t, true, errors.As(err, &errInvalidTag))
+	assert.Equal(t, 12, errInvalidTag.Column)
+}
+
+func TestTagQuotedValues(t *testing.T) {
+
+	var inputData = testTagQuotedMarshal{
+		Values: []string{"AB", "CD"},
+	}
+
+	result, err := Marshal(inputData, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("D AB,CD\x17   "), result)
+
+	var output testTagQuotedMarshal
+	position, err := Unmarshal([]byte("D AB,CD\x17N A"), &output, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 11, position)
+	assert.Equal(t, []string{"AB", "CD"}, output.Values)
+	assert.Equal(t, "", output.Comment)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Marshal(testTagInvalidMarshal{}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidTag *ErrorInvalidTag
+	assert.Equal(t, true, errors.Is(err, errInvalidTag))
+	assert.Contains(t, err.Error(), "'Invalid'")
+	assert.Contains(t, err.Error(), "column 10")
+}
@@ -2,6 +2,7 @@ package binfile
 
 import (
 	"errors"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
@@ -17,6 +18,12 @@ import (
 //
 // Check the README.md for usage.
 func Unmarshal(inputBytes []byte, target interface{}, enc Encoding, tz Timezone, arrayTerminator string) (int, error) {
+	return UnmarshalWithTagKey(inputBytes, target, defaultTagKey, enc, tz, arrayTerminator)
+}
+
+// Works like Unmarshal, but the annotations are read from the tags with the provided key, e.g. "bin_v2".
+// A field without such a tag falls back to its 'bin' tag, so one struct can describe several layouts.
+func UnmarshalWithTagKey(inputBytes []byte, target interface{}, tagKey string, enc Encoding, tz Timezone, arrayTerminator string) (int, error) {
 
 	// only pointers allowed
 	if reflect.ValueOf(target).Kind() != reflect.Ptr {
@@ -27,7 +34,7 @@ func Unmarshal(inputBytes []byte, target interface{}, enc Encoding, tz Timezone,
 	var targetKind = targetValue.Kind()
 	switch targetKind {
 	case reflect.Struct:
-		return internalUnmarshal(inputBytes, 0, targetValue, arrayTerminator, 1, enc, tz)
+		return internalUnmarshal(inputBytes, 0, targetValue, arrayTerminator, tagKey, 1, enc, tz)
 
 	case reflect.Slice:
 		var targetInnerKind = reflect.ValueOf(targetValue).Kind()
@@ -42,7 +49,7 @@ func Unmarshal(inputBytes []byte, target interface{}, enc Encoding, tz Timezone,
 
 			case reflect.Struct:
 
-				var processedBytes, err = internalUnmarshal(inputBytes[currentByte:], 0, outputTarget.Elem(), arrayTerminator, 1, enc, tz)
+				var processedBytes, err = internalUnmarshal(inputBytes[currentByte:], 0, outputTarget.Elem(), arrayTerminator, tagKey, 1, enc, tz)
 				if err != nil {
 					return currentByte + processedBytes, err
 				}
@@ -71,38 +78,90 @@ func Unmarshal(inputBytes []byte, target interface{}, enc Encoding, tz Timezone,
 }
 
 // use this for recursion
-func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value, arrayTerminator string, depth int, enc Encoding, tz Timezone) (int, error) {
+func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value, arrayTerminator string, tagKey string, depth int, enc Encoding, tz Timezone) (int, error) {
 
 	var initialStartByte = currentByte
 
+	var plan = getStructPlan(record.Type(), tagKey)
+	if plan.hasTerminator {
+		arrayTerminator = plan.terminator
+	}
+
+	// the start and end of every read field, needed for overlays, checksums and length fields
+	var fieldStartBytes, fieldEndBytes []int
+	if plan.tracksFieldPositions() {
+		fieldStartBytes, fieldEndBytes = newFieldPositions(record.NumField()), newFieldPositions(record.NumField())
+	}
+	var lastFieldNo = -1
+
+	// the input of the struct is bounded once a 'length:self' field is read
+	var structInputBytes = inputBytes
+
 	for fieldNo := 0; fieldNo < record.NumField(); fieldNo++ {
 
 		var recordField = record.Field(fieldNo)
+		var field = &plan.fields[fieldNo]
 
-		var binTag = record.Type().Field(fieldNo).Tag.Get("bin")
-		if !recordField.CanInterface() {
-			if binTag != "" {
-				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorExportedFieldNotAnnotated)
-			} else {
-				continue // TODO: this won't notify you about accidentally not exported nested structs
-			}
+		inputBytes = structInputBytes
+		if fieldEndBytes != nil && lastFieldNo != -1 {
+			fieldEndBytes[lastFieldNo], lastFieldNo = currentByte, -1
 		}
 
-		var annotationList, hasAnnotations = getAnnotationList(binTag)
+		if field.err != nil {
+			return currentByte, field.err
+		}
+		if field.hasCondition && !isConditionMet(record, field) {
+			continue // the field doesn't exist in this record
+		}
 
-		absoluteAnnotatedPos, relativeAnnotatedLength, hasAnnotatedAddress, err := getAddressAnnotation(annotationList)
-		if err != nil {
-			return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidAddressAnnotationError(err))
+		var binTag = field.binTag
+		var hasAnnotations = field.hasAnnotations
+		var absoluteAnnotatedPos, relativeAnnotatedLength, hasAnnotatedAddress = field.absoluteAnnotatedPos, field.relativeAnnotatedLength, field.hasAnnotatedAddress
+		var err error
+
+		// an optional field gets the zero value if the record ends before it
+		var isOptional = field.isOptional || plan.allowShort
+		var recordEnd = len(inputBytes)
+		if isOptional {
+			recordEnd = findRecordEnd(inputBytes, currentByte, arrayTerminator)
 		}
 
-		if hasAnnotatedAddress && absoluteAnnotatedPos > 0 {
+		if hasAnnotatedAddress && (absoluteAnnotatedPos > 0 || field.isRootRelative) {
 			// The current field has an absolute Address. This causes the cursor to be forwarded
 			var newPos = initialStartByte + absoluteAnnotatedPos
-			if len(inputBytes)-initialStartByte < newPos {
-				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newReadingOutOfBoundsError(newPos, newPos+relativeAnnotatedLength, len(inputBytes)-initialStartByte))
+			if field.isRootRelative { // counted from the start of the message
+				newPos = absoluteAnnotatedPos
+			}
+			if isOptional && newPos >= recordEnd {
+				setZeroValue(recordField)
+				fillDefaultValue(recordField, field)
+				continue
+			}
+			if newPos > len(inputBytes) {
+				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newReadingOutOfBoundsError(newPos, newPos+relativeAnnotatedLength, len(inputBytes)))
 			}
 			currentByte = newPos
 		}
+
+		if isOptional && currentByte >= recordEnd {
+			setZeroValue(recordField)
+			fillDefaultValue(recordField, field)
+			continue
+		}
+
+		if field.isConstant {
+			if currentByte, err = unmarshalConstant(inputBytes, currentByte, recordField, field); err != nil {
+				if fieldNo < record.NumField()-1 && errors.Is(err, ErrorFoundZeroValueBytes) {
+					continue
+				}
+				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
+			}
+			continue
+		}
+
+		if !recordField.CanInterface() {
+			continue
+		}
 		/*
 			// Really useful debugging:
 			for k := 0; k < depth; k++ {
@@ -112,12 +171,28 @@ func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value,
 				record.Type().Field(fieldNo).Name,
 				absoluteAnnotatedPos, relativeAnnotatedLength, currentByte)
 		*/
-		var valueKind = reflect.TypeOf(recordField.Interface()).Kind()
+		if fieldStartBytes != nil {
+			fieldStartBytes[fieldNo], lastFieldNo = currentByte, fieldNo
+		}
+
+		if field.hasBoundingField && fieldStartBytes[field.boundingFieldNo] != -1 {
+			// a length field before this one bounds it
+			var boundingLength = int(record.Field(field.boundingFieldNo).Int())
+			if boundingLength < 0 {
+				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newLengthMismatchError(boundingLength, 0))
+			}
+			if boundingLength > len(inputBytes)-currentByte {
+				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newReadingOutOfBoundsError(currentByte, currentByte+boundingLength, len(inputBytes)))
+			}
+			inputBytes = inputBytes[:currentByte+boundingLength]
+		}
+
+		var valueKind = field.valueKind
 
 		if valueKind == reflect.Struct {
 
 			var err error
-			currentByte, err = internalUnmarshal(inputBytes, currentByte, recordField, arrayTerminator, depth+1, enc, tz)
+			currentByte, err = internalUnmarshal(inputBytes, currentByte, recordField, arrayTerminator, tagKey, depth+1, enc, tz)
 			if err != nil { // If the nested structure did fail, then bail out
 				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
 			}
@@ -129,29 +204,60 @@ func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value,
 			continue // Do not process unannotated fields
 		}
 
-		if valueKind == reflect.Slice {
+		if field.hasOverlay { // a view reads the bytes of the overlaid field without moving the cursor
+			if overlayStartByte := fieldStartBytes[field.overlayFieldNo]; overlayStartByte != -1 && fieldEndBytes[field.overlayFieldNo]-overlayStartByte >= relativeAnnotatedLength {
+				if _, err = unmarshalSimpleTypes(inputBytes, overlayStartByte, recordField, relativeAnnotatedLength, field, depth+1, enc, tz); err != nil && !errors.Is(err, ErrorFoundZeroValueBytes) {
+					return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
+				}
+			}
+			continue
+		}
+
+		if field.hasLenPrefix && (valueKind == reflect.String || isByteSliceType(recordField.Type())) {
 
-			var arrayAnnotation, hasArrayAnnotation = getArrayAnnotation(annotationList)
-			if !hasArrayAnnotation {
-				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorMissingArrayAnnotation)
+			if currentByte, err = unmarshalLengthPrefixed(inputBytes, currentByte, recordField, field); err != nil {
+				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
 			}
 
+			continue
+		}
+
+		if valueKind == reflect.Slice {
+
 			var targetKind = reflect.TypeOf(recordField.Interface()).Elem().Kind()
-			if targetKind != reflect.Struct && !hasAnnotatedAddress {
-				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorMissingAddressAnnotation)
-			}
 
 			var arraySize = -1
-			var isTerminatorType = isArrayTypeTerminator(arrayAnnotation)
-			if !isTerminatorType {
-				if size, isFixedSize := getArrayFixedSize(arrayAnnotation); isFixedSize {
-					arraySize = size
-				} else if fieldName, isDynamic := getArraySizeFieldName(arrayAnnotation); isDynamic {
-					arraySize, err = resolveDynamicArraySize(record, fieldName)
-					if err != nil {
-						return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidDynamicArraySizeError(record.Type().Name(), fieldName, err))
+			var isTerminatorType = field.isTerminatorType
+			var fieldTerminator = arrayTerminator
+			if field.hasTerminator {
+				fieldTerminator = field.terminator
+			}
+			if field.hasLenPrefix { // the prefix holds the number of elements
+				if arraySize, currentByte, err = readLengthPrefix(inputBytes, currentByte, field.lenPrefixWidth, field.isLenPrefixBinary); err != nil {
+					return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
+				}
+			} else if field.isFixedSize {
+				arraySize = field.arrayFixedSize
+			} else if field.isDynamicSize {
+				if sizeFieldNo, isFieldFound := plan.fieldNo(field.arraySizeFieldName); isFieldFound && sizeFieldNo > fieldNo {
+					// the size field comes after the array, so it's read ahead
+					if err = unmarshalForwardField(inputBytes, initialStartByte, currentByte, record, sizeFieldNo, arrayTerminator, tagKey, depth, enc, tz); err != nil {
+						return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidDynamicArraySizeError(record.Type().Name(), field.arraySizeFieldName, err))
 					}
 				}
+				arraySize, err = resolveDynamicArraySize(record, field.arraySizeFieldName)
+				if err != nil {
+					return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newInvalidDynamicArraySizeError(record.Type().Name(), field.arraySizeFieldName, err))
+				}
+			}
+
+			// a fill type array repeats until the fields after it reach the end of the record
+			var fillEndByte = len(inputBytes)
+			if field.isFillType {
+				fillEndByte = findRecordEnd(inputBytes, currentByte, fieldTerminator)
+				if trailingSize, isStatic := sizeOfTrailingFields(record.Type(), tagKey, fieldNo+1); isStatic {
+					fillEndByte -= trailingSize
+				}
 			}
 
 			var targetType = recordField.Type()
@@ -167,6 +273,15 @@ func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value,
 						break
 					}
 				}
+				if field.isFillType && currentByte >= fillEndByte {
+					break
+				}
+				if arrayIdx > 0 && field.hasSeparator {
+					var isFound bool
+					if currentByte, isFound = advanceThroughTerminator(inputBytes, currentByte, field.separator); !isFound {
+						return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorSeparatorNotFound)
+					}
+				}
 
 				var outputTarget = reflect.New(targetType.Elem())
 				var lastByte = currentByte
@@ -174,7 +289,7 @@ func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value,
 				switch targetKind { // Nested: all here is an array of something
 				case reflect.Struct:
 
-					currentByte, err = internalUnmarshal(inputBytes, currentByte, outputTarget.Elem(), arrayTerminator, depth+1, enc, tz)
+					currentByte, err = internalUnmarshal(inputBytes, currentByte, outputTarget.Elem(), fieldTerminator, tagKey, depth+1, enc, tz)
 					if err != nil {
 						if !isTerminatorType && errors.Is(err, ErrorFoundZeroValueBytes) {
 							continue
@@ -184,7 +299,7 @@ func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value,
 
 				default:
 
-					currentByte, err = unmarshalSimpleTypes(inputBytes, currentByte, outputTarget.Elem(), relativeAnnotatedLength, annotationList, depth+1, enc, tz)
+					currentByte, err = unmarshalSimpleTypes(inputBytes, currentByte, outputTarget.Elem(), relativeAnnotatedLength, field, depth+1, enc, tz)
 					if err != nil {
 						if !isTerminatorType && errors.Is(err, ErrorFoundZeroValueBytes) {
 							continue
@@ -205,9 +320,9 @@ func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value,
 				}
 
 				// TODO: are we sure we need to check for a terminator in a fixed sized array's end? ref.: TestMarshalArrayWithFixedLength
-				if isTerminatorType || (!isTerminatorType && arrayIdx == arraySize-1) {
+				if isTerminatorType || (!isTerminatorType && !field.hasLenPrefix && arrayIdx == arraySize-1) {
 					var isFound bool
-					if currentByte, isFound = advanceThroughTerminator(inputBytes, currentByte, arrayTerminator); isFound {
+					if currentByte, isFound = advanceThroughTerminator(inputBytes, currentByte, fieldTerminator); isFound {
 						break
 					}
 				}
@@ -217,11 +332,23 @@ func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value,
 			continue
 		}
 
-		if !hasAnnotatedAddress {
-			return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, ErrorMissingAddressAnnotation)
+		var fieldStartByte = currentByte
+		if isOptional && currentByte+relativeAnnotatedLength > recordEnd {
+			// the record ends inside the field, a number is read from the bytes that are there
+			// and the missing bytes of the other types are read as trailing spaces
+			var shortBytes = append([]byte{}, inputBytes[currentByte:recordEnd]...)
+			var valueType = recordField.Type()
+			if valueType.Kind() == reflect.Ptr {
+				valueType = valueType.Elem()
+			}
+			if !isNumericKind(valueType.Kind()) {
+				shortBytes, _ = appendPaddingBytes(shortBytes, currentByte+relativeAnnotatedLength-recordEnd, ' ')
+			}
+			_, err = unmarshalSimpleTypes(shortBytes, 0, recordField, len(shortBytes), field, depth+1, enc, tz)
+			currentByte = recordEnd
+		} else {
+			currentByte, err = unmarshalSimpleTypes(inputBytes, currentByte, recordField, relativeAnnotatedLength, field, depth+1, enc, tz)
 		}
-
-		currentByte, err = unmarshalSimpleTypes(inputBytes, currentByte, recordField, relativeAnnotatedLength, annotationList, depth+1, enc, tz)
 		if err != nil {
 			// the last item should actually return the error but itmes before should process to advance the current byte
 			if fieldNo < record.NumField()-1 && errors.Is(err, ErrorFoundZeroValueBytes) {
@@ -229,17 +356,209 @@ func internalUnmarshal(inputBytes []byte, currentByte int, record reflect.Value,
 			}
 			return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, err)
 		}
+		fillDefaultValue(recordField, field)
+
+		if field.hasNullFlag {
+			record.Field(field.nullFlagFieldNo).SetBool(isNullSentinel(inputBytes[fieldStartByte:currentByte], field))
+		}
+
+		if field.hasLength && field.lengthFieldNo == -1 {
+			var recordEndByte = initialStartByte + int(recordField.Int())
+			if recordEndByte < currentByte {
+				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newLengthMismatchError(int(recordField.Int()), currentByte-initialStartByte))
+			}
+			if recordEndByte > len(structInputBytes) {
+				return currentByte, newProcessingFieldError(record.Type().Field(fieldNo).Name, binTag, newReadingOutOfBoundsError(initialStartByte, recordEndByte, len(structInputBytes)))
+			}
+			structInputBytes = structInputBytes[:recordEndByte]
+		}
+	}
+
+	if fieldEndBytes != nil && lastFieldNo != -1 {
+		fieldEndBytes[lastFieldNo] = currentByte
+	}
+
+	// the declared lengths are verified after the whole struct is read, as they can be of fields after them
+	if plan.hasLengths {
+		for fieldNo := range plan.fields {
+			var field = &plan.fields[fieldNo]
+			if !field.hasLength || fieldStartBytes[fieldNo] == -1 {
+				continue
+			}
+			var declaredLength, actualLength = int(record.Field(fieldNo).Int()), measureLength(field, initialStartByte, currentByte, fieldStartBytes, fieldEndBytes)
+			if declaredLength != actualLength {
+				return currentByte, newProcessingFieldError(field.name, field.binTag, newLengthMismatchError(declaredLength, actualLength))
+			}
+		}
+	}
+
+	// the check values are verified after the whole struct is read, as their range can be anywhere in it
+	if plan.hasChecksums {
+		for fieldNo := range plan.fields {
+			if !plan.fields[fieldNo].hasChecksum || fieldStartBytes[fieldNo] == -1 {
+				continue
+			}
+			if err := verifyChecksum(inputBytes[initialStartByte:currentByte], fieldStartBytes[fieldNo]-initialStartByte, record.Field(fieldNo), &plan.fields[fieldNo]); err != nil {
+				return currentByte, newProcessingFieldError(plan.fields[fieldNo].name, plan.fields[fieldNo].binTag, err)
+			}
+		}
 	}
 
 	return currentByte, nil
 }
 
-// use this for processing end nodes
-func unmarshalSimpleTypes(inputBytes []byte, currentByte int, recordField reflect.Value, relativeAnnotatedLength int, annotationList []string, depth int, enc Encoding, tz Timezone) (int, error) {
+// Computes the check value of the field over 'structBytes' and compares it with the read value of the field.
+// A string field holds the value as hex digits, an integer as a decimal number.
+func verifyChecksum(structBytes []byte, fieldStartByte int, recordField reflect.Value, field *fieldPlan) error {
+
+	var expected, err = computeChecksum(structBytes, fieldStartByte, field)
+	if err != nil {
+		return err
+	}
+
+	var actual uint64
+	if recordField.Kind() == reflect.String {
+		if actual, err = strconv.ParseUint(strings.TrimSpace(recordField.String()), 16, 64); err != nil {
+			return err
+		}
+	} else {
+		actual = uint64(recordField.Int())
+	}
+
+	if actual != expected {
+		return newChecksumMismatchError(expected, actual)
+	}
+
+	return nil
+}
+
+// Reads the value of the field 'sizeFieldNo' which comes later in the struct ahead of time, so it can be used as an array size.
+// The position of the field is either annotated as absolute or counted back from the end of the record.
+func unmarshalForwardField(inputBytes []byte, initialStartByte int, currentByte int, record reflect.Value, sizeFieldNo int, arrayTerminator string, tagKey string, depth int, enc Encoding, tz Timezone) error {
+
+	var sizeField = &getStructPlan(record.Type(), tagKey).fields[sizeFieldNo]
+	if sizeField.err != nil {
+		return sizeField.err
+	}
+
+	var sizeFieldPos int
+	if sizeField.isRootRelative {
+		sizeFieldPos = sizeField.absoluteAnnotatedPos
+	} else if sizeField.absoluteAnnotatedPos != -1 {
+		sizeFieldPos = initialStartByte + sizeField.absoluteAnnotatedPos
+	} else {
+		var trailingSize, isStatic = sizeOfTrailingFields(record.Type(), tagKey, sizeFieldNo)
+		if !isStatic || sizeField.valueKind == reflect.Struct || sizeField.valueKind == reflect.Slice {
+			return ErrorUnknownFieldPosition
+		}
+		sizeFieldPos = findRecordEnd(inputBytes, currentByte, arrayTerminator) - trailingSize
+	}
+
+	if sizeFieldPos < currentByte || sizeFieldPos+sizeField.relativeAnnotatedLength > len(inputBytes) {
+		return newReadingOutOfBoundsError(sizeFieldPos, sizeFieldPos+sizeField.relativeAnnotatedLength, len(inputBytes))
+	}
+
+	var _, err = unmarshalSimpleTypes(inputBytes, sizeFieldPos, record.Field(sizeFieldNo), sizeField.relativeAnnotatedLength, sizeField, depth+1, enc, tz)
+	return err
+}
+
+// Checks if the bytes at 'currentByte' match the value of the 'const' annotation. An exported string field is set to the read value.
+//
+// Returns the position after the value or an error.
+func unmarshalConstant(inputBytes []byte, currentByte int, recordField reflect.Value, field *fieldPlan) (int, error) {
+
+	var endByte = currentByte + field.relativeAnnotatedLength
+	if endByte > len(inputBytes) {
+		return currentByte, newReadingOutOfBoundsError(currentByte, endByte, len(inputBytes))
+	}
+
+	var actual = string(inputBytes[currentByte:endByte])
+	if actual == string(make([]byte, field.relativeAnnotatedLength)) {
+		return endByte, ErrorFoundZeroValueBytes
+	}
+	if actual != field.constValue {
+		return currentByte, newConstantMismatchError(field.constValue, actual)
+	}
+
+	if recordField.Kind() == reflect.String && recordField.CanSet() {
+		if field.isTrimmed {
+			actual = strings.TrimSpace(actual)
+		}
+		recordField.SetString(actual)
+	}
+
+	return endByte, nil
+}
+
+// Reads a string or byte slice preceded by its length into 'recordField'.
+//
+// Returns the position after the value or an error.
+func unmarshalLengthPrefixed(inputBytes []byte, currentByte int, recordField reflect.Value, field *fieldPlan) (int, error) {
+
+	var length, payloadStartByte, err = readLengthPrefix(inputBytes, currentByte, field.lenPrefixWidth, field.isLenPrefixBinary)
+	if err != nil {
+		return currentByte, err
+	}
+
+	if field.relativeAnnotatedLength > 0 && length > field.relativeAnnotatedLength {
+		return currentByte, newInvalidValueLengthError(string(inputBytes[currentByte:payloadStartByte]), length)
+	}
+	if length > len(inputBytes)-payloadStartByte { // the sum could overflow with a hostile prefix
+		return currentByte, newReadingOutOfBoundsError(payloadStartByte, len(inputBytes), len(inputBytes))
+	}
+
+	if !recordField.CanSet() {
+		return currentByte, ErrorAnnotatedFieldNotWritable
+	}
+
+	var payload = inputBytes[payloadStartByte : payloadStartByte+length]
+	if recordField.Kind() == reflect.String {
+		var strvalue = string(payload)
+		if field.isTrimmed {
+			strvalue = strings.TrimSpace(strvalue)
+		}
+		recordField.SetString(strvalue)
+	} else {
+		recordField.SetBytes(append([]byte{}, payload...))
+	}
+
+	return payloadStartByte + length, nil
+}
+
+// Reads a length prefix of 'width' bytes. It's either ASCII digits (optionally padded with spaces) or a big-endian binary number.
+//
+// Returns the length and the position after the prefix or an error.
+func readLengthPrefix(inputBytes []byte, currentByte int, width int, isBinary bool) (int, int, error) {
+
+	if currentByte+width > len(inputBytes) {
+		return 0, currentByte, newReadingOutOfBoundsError(currentByte, currentByte+width, len(inputBytes))
+	}
+
+	var prefixBytes = inputBytes[currentByte : currentByte+width]
+	var length = 0
+	if isBinary {
+		for _, val := range prefixBytes {
+			length = length<<8 | int(val)
+		}
+	} else {
+		var err error
+		if length, err = strconv.Atoi(strings.TrimSpace(string(prefixBytes))); err != nil {
+			return 0, currentByte, err
+		}
+	}
+	if length < 0 { // a binary prefix as wide as an int can have the sign bit set
+		return 0, currentByte, newInvalidSizeForArrayError(length)
+	}
+
+	return length, currentByte + width, nil
+}
+
+// use this for processing end nodes. The value is parsed according to the processed annotations of the field plan.
+func unmarshalSimpleTypes(inputBytes []byte, currentByte int, recordField reflect.Value, relativeAnnotatedLength int, field *fieldPlan, depth int, enc Encoding, tz Timezone) (int, error) {
 
 	if relativeAnnotatedLength > 0 {
 		// Having a length, the total length is not supposed to exceed the boundaries of the input
-		if currentByte+relativeAnnotatedLength-1 > len(inputBytes) {
+		if currentByte+relativeAnnotatedLength > len(inputBytes) {
 			return currentByte, newReadingOutOfBoundsError(currentByte, currentByte+relativeAnnotatedLength, len(inputBytes))
 		}
 	}
@@ -256,14 +575,39 @@ func unmarshalSimpleTypes(inputBytes []byte, currentByte int, recordField reflec
 		return currentByte, ErrorAnnotatedFieldNotWritable
 	}
 
+	if field.isBlankZero && isBlankBytes(inputBytes[currentByte:currentByte+relativeAnnotatedLength]) {
+		recordField.Set(reflect.Zero(recordField.Type())) // zero value or nil for pointers
+		return currentByte + relativeAnnotatedLength, nil
+	}
+
 	var valueKind = reflect.TypeOf(recordField.Interface()).Kind()
+
+	if isNullSentinel(inputBytes[currentByte:currentByte+relativeAnnotatedLength], field) {
+		if valueKind == reflect.Float32 || valueKind == reflect.Float64 {
+			recordField.SetFloat(math.NaN())
+		} else {
+			recordField.Set(reflect.Zero(recordField.Type())) // zero value or nil for pointers
+		}
+		return currentByte + relativeAnnotatedLength, nil
+	}
+
 	switch valueKind {
+	case reflect.Ptr:
+
+		var target = reflect.New(recordField.Type().Elem())
+		var err error
+		if currentByte, err = unmarshalSimpleTypes(inputBytes, currentByte, target.Elem(), relativeAnnotatedLength, field, depth, enc, tz); err != nil {
+			return currentByte, err
+		}
+
+		recordField.Set(target)
+
 	case reflect.String:
 
 		strvalue := string(inputBytes[currentByte : currentByte+relativeAnnotatedLength])
 		currentByte += relativeAnnotatedLength
 
-		if hasAnnotationTrim(annotationList) {
+		if field.isTrimmed {
 			strvalue = strings.TrimSpace(strvalue)
 		}
 
@@ -274,15 +618,26 @@ func unmarshalSimpleTypes(inputBytes []byte, currentByte int, recordField reflec
 		strvalue := string(inputBytes[currentByte : currentByte+relativeAnnotatedLength])
 		currentByte += relativeAnnotatedLength
 
-		if hasAnnotationPadspace(annotationList) {
+		if field.isPadSpace {
 			strvalue = strings.Replace(strvalue, " ", "", -1) // ex.: "-  3"
 		}
 
+		var err error
+		if strvalue, err = normalizeSignedNumber(strvalue, field); err != nil {
+			return currentByte, err
+		}
+
 		num, err := strconv.Atoi(strvalue)
 		if err != nil {
 			return currentByte, err
 		}
 
+		if field.isScaled {
+			if num, err = scaledValueToInt(scaleToField(float64(num), field.scale, field.offset)); err != nil {
+				return currentByte, err
+			}
+		}
+
 		reflect.ValueOf(recordField.Addr().Interface()).Elem().Set(reflect.ValueOf(num))
 
 	case reflect.Float32:
@@ -290,15 +645,24 @@ func unmarshalSimpleTypes(inputBytes []byte, currentByte int, recordField reflec
 		strvalue := string(inputBytes[currentByte : currentByte+relativeAnnotatedLength])
 		currentByte += relativeAnnotatedLength
 
-		if hasAnnotationPadspace(annotationList) {
+		if field.isPadSpace {
 			strvalue = strings.Replace(strvalue, " ", "", -1) // ex.: "-  3.1"
 		}
 
+		var err error
+		if strvalue, err = normalizeSignedNumber(strvalue, field); err != nil {
+			return currentByte, err
+		}
+
 		num, err := strconv.ParseFloat(strvalue, 32)
 		if err != nil {
 			return currentByte, err
 		}
 
+		if field.isScaled {
+			num = scaleToField(num, field.scale, field.offset)
+		}
+
 		reflect.ValueOf(recordField.Addr().Interface()).Elem().Set(reflect.ValueOf(float32(num)))
 
 	case reflect.Float64:
@@ -306,15 +670,24 @@ func unmarshalSimpleTypes(inputBytes []byte, currentByte int, recordField reflec
 		strvalue := string(inputBytes[currentByte : currentByte+relativeAnnotatedLength])
 		currentByte += relativeAnnotatedLength
 
-		if hasAnnotationPadspace(annotationList) {
+		if field.isPadSpace {
 			strvalue = strings.Replace(strvalue, " ", "", -1) // ex.: "-  3.1"
 		}
 
+		var err error
+		if strvalue, err = normalizeSignedNumber(strvalue, field); err != nil {
+			return currentByte, err
+		}
+
 		num, err := strconv.ParseFloat(strvalue, 64)
 		if err != nil {
 			return currentByte, err
 		}
 
+		if field.isScaled {
+			num = scaleToField(num, field.scale, field.offset)
+		}
+
 		reflect.ValueOf(recordField.Addr().Interface()).Elem().Set(reflect.ValueOf(float64(num)))
 
 	default:
@@ -324,3 +697,61 @@ func unmarshalSimpleTypes(inputBytes []byte, currentByte int, recordField reflec
 
 	return currentByte, nil
 }
+
+// Converts a read number in 'strvalue' that follows the annotated sign convention into a form accepted by strconv.
+// Gives an error if the sign doesn't follow the convention.
+func normalizeSignedNumber(strvalue string, field *fieldPlan) (string, error) {
+
+	var sign = field.signConvention
+	if sign == signLeading {
+		return strvalue, nil
+	}
+
+	var isNegative = false
+	var body = strvalue
+	switch sign {
+	case signTrailing:
+		if strings.HasSuffix(body, "-") {
+			isNegative = true
+			body = body[:len(body)-1]
+		} else if strings.HasSuffix(body, "+") {
+			body = body[:len(body)-1]
+		}
+	case signParen:
+		if strings.HasPrefix(body, "(") {
+			if !strings.HasSuffix(body, ")") {
+				return strvalue, newInvalidSignFormatError(strvalue, sign)
+			}
+			isNegative = true
+			body = body[1 : len(body)-1]
+		}
+	}
+
+	// the sign of an exponent is fine, any other sign is misplaced for this convention
+	if strings.HasPrefix(body, "-") || strings.HasPrefix(body, "+") || strings.ContainsAny(body, "()") {
+		return strvalue, newInvalidSignFormatError(strvalue, sign)
+	}
+
+	if isNegative {
+		return "-" + body, nil
+	}
+	return body, nil
+}
+
+// Sets the field to the value of its 'default' annotation if it holds the zero value (nil for pointers)
+// and the field has the 'filldefault' annotation.
+func fillDefaultValue(recordField reflect.Value, field *fieldPlan) {
+
+	if !field.isDefaultFilled || !recordField.CanSet() || !recordField.IsZero() {
+		return
+	}
+
+	if recordField.Kind() == reflect.Ptr {
+		var defaultPtr = reflect.New(field.defaultValue.Type())
+		defaultPtr.Elem().Set(field.defaultValue)
+		recordField.Set(defaultPtr)
+		return
+	}
+
+	recordField.Set(field.defaultValue)
+}
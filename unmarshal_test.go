@@ -2,6 +2,7 @@ package binfile
 
 import (
 	"errors"
+	"math"
 	"reflect"
 	"testing"
 
@@ -564,3 +565,749 @@ func TestUnmarshalDynamicArray(t *testing.T) {
 	assert.Equal(t, true, errors.Is(err, ErrorUnknownFieldName))
 	assert.Equal(t, 1, position)
 }
+
+//
+//-Blank Zero------------------------------------------------------------------
+
+type testBlankZeroUnmarshal struct {
+	IntField     int      `bin:":3,blankzero"`
+	FloatField   float32  `bin:":4,blankzero"`
+	NonZeroField int      `bin:":3,blankzero"`
+	NilPointer   *int     `bin:":2,blankzero"`
+	PointerField *float64 `bin:":9,blankzero"`
+}
+
+func TestUnmarshalBlankZero(t *testing.T) {
+
+	var inputData = []byte("       007  01.50E+00")
+
+	var result testBlankZeroUnmarshal
+	position, err := Unmarshal(inputData, &result, EncodingUTF8, TimezoneUTC, "\r")
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputData), position)
+
+	assert.Equal(t, 0, result.IntField)
+	assert.Equal(t, float32(0), result.FloatField)
+	assert.Equal(t, 7, result.NonZeroField)
+	assert.Nil(t, result.NilPointer)
+	assert.NotNil(t, result.PointerField)
+	assert.Equal(t, 1.5, *result.PointerField)
+
+	//-------------------------------------------------------------------------
+
+	var resultWithoutAnnotation testIntUnmarshal
+	_, err = Unmarshal([]byte("  "), &resultWithoutAnnotation, EncodingUTF8, TimezoneUTC, "\r")
+	assert.NotNil(t, err)
+}
+
+//
+//-Sign Conventions------------------------------------------------------------
+
+type testSignConventionUnmarshal struct {
+	Leading           int     `bin:":4,sign:leading"`
+	Trailing          int     `bin:":4,sign:trailing"`
+	TrailingForced    int     `bin:":4,sign:trailing,forcesign"`
+	TrailingPadspace  int     `bin:":4,sign:trailing,padspace"`
+	None              int     `bin:":4,sign:none"`
+	Paren             int     `bin:":5,sign:paren"`
+	ParenPositive     int     `bin:":5,sign:paren"`
+	TrailingFloat     float32 `bin:":6,sign:trailing"`
+	ParenFloatPadding float32 `bin:":7,sign:paren,padspace"`
+}
+
+type testSignConventionStrictUnmarshal struct {
+	Field int `bin:":4,sign:trailing"`
+}
+
+func TestUnmarshalSignConvention(t *testing.T) {
+
+	var inputData = []byte("-064064-064+ 64-0064(064)0006406.40-(  6.4)")
+
+	var result testSignConventionUnmarshal
+	position, err := Unmarshal(inputData, &result, EncodingUTF8, TimezoneUTC, "\r")
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputData), position)
+
+	assert.Equal(t, -64, result.Leading)
+	assert.Equal(t, -64, result.Trailing)
+	assert.Equal(t, 64, result.TrailingForced)
+	assert.Equal(t, -64, result.TrailingPadspace)
+	assert.Equal(t, 64, result.None)
+	assert.Equal(t, -64, result.Paren)
+	assert.Equal(t, 64, result.ParenPositive)
+	assert.Equal(t, float32(-6.4), result.TrailingFloat)
+	assert.Equal(t, float32(-6.4), result.ParenFloatPadding)
+
+	//-------------------------------------------------------------------------
+
+	var resultStrict testSignConventionStrictUnmarshal
+	_, err = Unmarshal([]byte("-064"), &resultStrict, EncodingUTF8, TimezoneUTC, "\r")
+
+	var errInvalidSignFormat *ErrorInvalidSignFormat
+	assert.Equal(t, true, errors.Is(err, errInvalidSignFormat))
+}
+
+//
+//-Null Sentinels--------------------------------------------------------------
+
+type testNullSentinelUnmarshal struct {
+	NilPointer   *float32 `bin:":5,null:*****"`
+	NaNFloat     float64  `bin:":5,null:ERR,null:-----"`
+	OtherNaN     float32  `bin:":5,null:ERR,null:-----"`
+	IsFlagged    bool
+	FlaggedInt   int `bin:":4,null:----,nullflag:IsFlagged"`
+	IsNotFlagged bool
+	RegularInt   int `bin:":4,null:----,nullflag:IsNotFlagged"`
+}
+
+func TestUnmarshalNullSentinel(t *testing.T) {
+
+	var inputData = []byte("*****  ERR---------0012")
+
+	var result testNullSentinelUnmarshal
+	position, err := Unmarshal(inputData, &result, EncodingUTF8, TimezoneUTC, "\r")
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputData), position)
+
+	assert.Nil(t, result.NilPointer)
+	assert.Equal(t, true, math.IsNaN(result.NaNFloat))
+	assert.Equal(t, true, math.IsNaN(float64(result.OtherNaN)))
+	assert.Equal(t, true, result.IsFlagged)
+	assert.Equal(t, 0, result.FlaggedInt)
+	assert.Equal(t, false, result.IsNotFlagged)
+	assert.Equal(t, 12, result.RegularInt)
+}
+
+//
+//-Scaling---------------------------------------------------------------------
+
+type testScalingUnmarshal struct {
+	Hemoglobin  float32 `bin:":4,scale:0.1"`
+	Hundredths  int     `bin:":4,scale:0.01"`
+	WithOffset  float64 `bin:":9,scale:2,offset:-40"`
+	ScaledArray []int   `bin:"array:2,:3,scale:10"`
+}
+
+type testScalingInvalidUnmarshal struct {
+	Invalid int `bin:":4,scale:0"`
+}
+
+func TestUnmarshalScaling(t *testing.T) {
+
+	var inputData = []byte("006412001.050E+01001025")
+
+	var result testScalingUnmarshal
+	position, err := Unmarshal(inputData, &result, EncodingUTF8, TimezoneUTC, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputData), position)
+
+	assert.Equal(t, float32(6.4), result.Hemoglobin)
+	assert.Equal(t, 12, result.Hundredths)
+	assert.Equal(t, float64(-19), result.WithOffset)
+	assert.Equal(t, true, reflect.DeepEqual(result.ScaledArray, []int{10, 250}))
+
+	//-------------------------------------------------------------------------
+
+	var resultInvalid testScalingInvalidUnmarshal
+	_, err = Unmarshal([]byte("0012"), &resultInvalid, EncodingUTF8, TimezoneUTC, "")
+
+	var errInvalidScaling *ErrorInvalidScaling
+	assert.Equal(t, true, errors.Is(err, errInvalidScaling))
+}
+
+//
+//-Forward References and Fill Arrays------------------------------------------
+
+type testForwardReferenceUnmarshal struct {
+	Items   []int  `bin:"array:Count,:2"`
+	Trailer string `bin:":1"`
+	Count   int    `bin:":1"`
+}
+
+type testForwardReferenceAbsoluteUnmarshal struct {
+	Items []int `bin:"array:Count,:1"`
+	Count int   `bin:"5:1"`
+}
+
+type testFillArrayUnmarshal struct {
+	Items   []testArrayInnerUnmarshal `bin:"array:fill"`
+	Trailer string                    `bin:":3"`
+}
+
+func TestUnmarshalForwardReferenceAndFillArray(t *testing.T) {
+
+	var inputForward = []byte("1122X2")
+
+	var resultForward testForwardReferenceUnmarshal
+	position, err := Unmarshal(inputForward, &resultForward, EncodingUTF8, TimezoneUTC, "\r")
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputForward), position)
+
+	assert.Equal(t, true, reflect.DeepEqual(resultForward.Items, []int{11, 22}))
+	assert.Equal(t, "X", resultForward.Trailer)
+	assert.Equal(t, 2, resultForward.Count)
+
+	//-------------------------------------------------------------------------
+
+	var inputAbsolute = []byte("123xx3")
+
+	var resultAbsolute testForwardReferenceAbsoluteUnmarshal
+	position, err = Unmarshal(inputAbsolute, &resultAbsolute, EncodingUTF8, TimezoneUTC, "\r")
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputAbsolute), position)
+	assert.Equal(t, true, reflect.DeepEqual(resultAbsolute.Items, []int{1, 2, 3}))
+
+	//-------------------------------------------------------------------------
+
+	var inputFill = []byte("123456END\rOTHER")
+
+	var resultFill testFillArrayUnmarshal
+	position, err = Unmarshal(inputFill, &resultFill, EncodingUTF8, TimezoneUTC, "\r")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 9, position)
+
+	assert.Equal(t, 3, len(resultFill.Items))
+	assert.Equal(t, 5, resultFill.Items[2].InnerData1)
+	assert.Equal(t, 6, resultFill.Items[2].InnerData2)
+	assert.Equal(t, "END", resultFill.Trailer)
+}
+
+//
+//-Length Prefix---------------------------------------------------------------
+
+type testLengthPrefixUnmarshal struct {
+	Comment string                    `bin:"lenprefix:3"`
+	Raw     []byte                    `bin:"lenprefix:2:binary"`
+	Items   []testArrayInnerUnmarshal `bin:"lenprefix:1"`
+	Ints    []int                     `bin:"lenprefix:2,:1"`
+	After   string                    `bin:"32:2"`
+}
+
+func TestUnmarshalLengthPrefix(t *testing.T) {
+
+	var inputData = []byte("012Hello World!\x00\x03abc2123403123xxZZ")
+
+	var result testLengthPrefixUnmarshal
+	position, err := Unmarshal(inputData, &result, EncodingUTF8, TimezoneUTC, "\r")
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputData), position)
+
+	assert.Equal(t, "Hello World!", result.Comment)
+	assert.Equal(t, []byte("abc"), result.Raw)
+	assert.Equal(t, 2, len(result.Items))
+	assert.Equal(t, 4, result.Items[1].InnerData2)
+	assert.Equal(t, true, reflect.DeepEqual(result.Ints, []int{1, 2, 3}))
+	assert.Equal(t, "ZZ", result.After)
+
+	//-------------------------------------------------------------------------
+
+	var resultOutOfBounds testLengthPrefixUnmarshal
+	_, err = Unmarshal([]byte("099Hello"), &resultOutOfBounds, EncodingUTF8, TimezoneUTC, "\r")
+
+	var errOutOfBounds *ErrorReadingOutOfBounds
+	assert.Equal(t, true, errors.Is(err, errOutOfBounds))
+
+	//-------------------------------------------------------------------------
+
+	// hostile prefixes must give an error instead of a panic
+	var resultBinary testLengthPrefixHostileUnmarshal
+	_, err = Unmarshal([]byte("\xff\xff\xff\xff\xff\xff\xff\xffabc"), &resultBinary, EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidSize *ErrorInvalidSizeForArray
+	assert.Equal(t, true, errors.Is(err, errInvalidSize))
+
+	var resultASCII testLengthPrefixHostileASCIIUnmarshal
+	_, err = Unmarshal([]byte("9223372036854775807abc"), &resultASCII, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errOutOfBounds))
+
+	_, err = Unmarshal([]byte("abc"), &testLengthPrefixTooWideUnmarshal{}, EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidLengthPrefix *ErrorInvalidLengthPrefix
+	assert.Equal(t, true, errors.Is(err, errInvalidLengthPrefix))
+}
+
+type testLengthPrefixHostileUnmarshal struct {
+	S string `bin:"lenprefix:8:binary"`
+}
+
+type testLengthPrefixHostileASCIIUnmarshal struct {
+	S string `bin:"lenprefix:19"`
+}
+
+type testLengthPrefixTooWideUnmarshal struct {
+	S string `bin:"lenprefix:9:binary"`
+}
+
+//
+//-Constants-------------------------------------------------------------------
+
+type testConstantUnmarshal struct {
+	_          string `bin:"const:D,:2"`
+	RecordType string `bin:"const:R"`
+	Value      int    `bin:":3"`
+	recordEnd  string `bin:"const:END"`
+}
+
+func TestUnmarshalConstant(t *testing.T) {
+
+	var result testConstantUnmarshal
+	position, err := Unmarshal([]byte("D R042END"), &result, EncodingUTF8, TimezoneUTC, "\r")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 9, position)
+	assert.Equal(t, "R", result.RecordType)
+	assert.Equal(t, 42, result.Value)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Unmarshal([]byte("X R042END"), &result, EncodingUTF8, TimezoneUTC, "\r")
+
+	var errConstantMismatch *ErrorConstantMismatch
+	assert.Equal(t, true, errors.As(err, &errConstantMismatch))
+	assert.Equal(t, "D ", errConstantMismatch.Expected)
+	assert.Equal(t, "X ", errConstantMismatch.Actual)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Unmarshal([]byte("D R042EOF"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errConstantMismatch))
+}
+
+//
+//-Conditional Fields----------------------------------------------------------
+
+type testConditionUnmarshal struct {
+	Diluted  string `bin:":1"`
+	Dilution int    `bin:":3,if:Diluted=Y"`
+	Comment  string `bin:":2,if:Diluted!=Y"`
+	Value    int    `bin:":2"`
+}
+
+type testConditionAfterUnmarshal struct {
+	Dilution int    `bin:":3,if:Diluted=Y"`
+	Diluted  string `bin:":1"`
+}
+
+func TestUnmarshalCondition(t *testing.T) {
+
+	var result testConditionUnmarshal
+	position, err := Unmarshal([]byte("Y01005"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 6, position)
+	assert.Equal(t, testConditionUnmarshal{Diluted: "Y", Dilution: 10, Value: 5}, result)
+
+	//-------------------------------------------------------------------------
+
+	var resultNotDiluted testConditionUnmarshal
+	position, err = Unmarshal([]byte("NAB05"), &resultNotDiluted, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, position)
+	assert.Equal(t, testConditionUnmarshal{Diluted: "N", Comment: "AB", Value: 5}, resultNotDiluted)
+
+	//-------------------------------------------------------------------------
+
+	var resultAfter testConditionAfterUnmarshal
+	_, err = Unmarshal([]byte("010Y"), &resultAfter, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorConditionFieldNotBefore))
+}
+
+//
+//-Short Records---------------------------------------------------------------
+
+type testOptionalUnmarshal struct {
+	SampleId string `bin:":4"`
+	Comment  string `bin:":5,trim,optional"`
+	Value    *int   `bin:":3,optional"`
+}
+
+type testRequiredUnmarshal struct {
+	SampleId string `bin:":4"`
+	Comment  string `bin:":5,trim"`
+}
+
+type testOptionalNumberUnmarshal struct {
+	SampleId string   `bin:":4"`
+	Count    int      `bin:":3,optional"`
+	Result   *float64 `bin:":6,optional"`
+}
+
+type testAllowShortUnmarshal struct {
+	_        struct{} `bin:"allowshort"`
+	SampleId string   `bin:":4"`
+	Count    int      `bin:":2"`
+	Result   float32  `bin:":5"`
+}
+
+func TestUnmarshalOptionalFields(t *testing.T) {
+
+	var result testOptionalUnmarshal
+	position, err := Unmarshal([]byte("ID01AB"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 6, position)
+	assert.Equal(t, "ID01", result.SampleId)
+	assert.Equal(t, "AB", result.Comment)
+	assert.Nil(t, result.Value)
+
+	//-------------------------------------------------------------------------
+
+	var resultTerminated = testOptionalUnmarshal{Comment: "previous"}
+	position, err = Unmarshal([]byte("ID02\rID03"), &resultTerminated, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, position)
+	assert.Equal(t, "ID02", resultTerminated.SampleId)
+	assert.Equal(t, "", resultTerminated.Comment)
+
+	//-------------------------------------------------------------------------
+
+	var resultRequired testRequiredUnmarshal
+	_, err = Unmarshal([]byte("ID01AB"), &resultRequired, EncodingUTF8, TimezoneUTC, "\r")
+
+	var errOutOfBounds *ErrorReadingOutOfBounds
+	assert.Equal(t, true, errors.Is(err, errOutOfBounds))
+
+	//-------------------------------------------------------------------------
+
+	// a number cut off by the end of the record is read without the missing bytes
+	var resultNumber testOptionalNumberUnmarshal
+	position, err = Unmarshal([]byte("ID011\r"), &resultNumber, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, position)
+	assert.Equal(t, 1, resultNumber.Count)
+	assert.Nil(t, resultNumber.Result)
+
+	var resultFloat testOptionalNumberUnmarshal
+	_, err = Unmarshal([]byte("ID010071.5"), &resultFloat, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 7, resultFloat.Count)
+	assert.Equal(t, 1.5, *resultFloat.Result)
+}
+
+func TestUnmarshalAllowShort(t *testing.T) {
+
+	var inputData = []byte("ID010501.50\rID02\rID0307\r")
+
+	var result []testAllowShortUnmarshal
+	position, err := Unmarshal(inputData, &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, len(inputData), position)
+
+	assert.Equal(t, []testAllowShortUnmarshal{
+		{SampleId: "ID01", Count: 5, Result: 1.5},
+		{SampleId: "ID02"},
+		{SampleId: "ID03", Count: 7},
+	}, result)
+
+	//-------------------------------------------------------------------------
+
+	// on a real field the marker is an error instead of turning the field into a marker
+	var resultMisplaced testAllowShortMisplacedUnmarshal
+	_, err = Unmarshal([]byte("ABC"), &resultMisplaced, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorMisplacedStructAnnotation))
+
+	_, err = Marshal(testAllowShortMisplacedUnmarshal{A: "ABC"}, ' ', EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorMisplacedStructAnnotation))
+
+	var errs = Validate(testAllowShortMisplacedUnmarshal{})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, true, errors.Is(errs[0], ErrorMisplacedStructAnnotation))
+}
+
+type testAllowShortMisplacedUnmarshal struct {
+	A string `bin:":3,allowshort"`
+}
+
+//
+//-Root Relative Positions-----------------------------------------------------
+
+type testRootPositionInnerUnmarshal struct {
+	Code  string `bin:":2"`
+	Gap   string `bin:"3:1"`
+	Value int    `bin:"@10:3"`
+}
+
+type testRootPositionUnmarshal struct {
+	RecordType string `bin:":2"`
+	Inner      testRootPositionInnerUnmarshal
+	Tail       string `bin:"@14:2"`
+}
+
+func TestUnmarshalRootRelativePosition(t *testing.T) {
+
+	var result testRootPositionUnmarshal
+	position, err := Unmarshal([]byte("D AB X    042 ZZ"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 16, position)
+
+	assert.Equal(t, "D ", result.RecordType)
+	assert.Equal(t, "AB", result.Inner.Code)
+	assert.Equal(t, "X", result.Inner.Gap)
+	assert.Equal(t, 42, result.Inner.Value)
+	assert.Equal(t, "ZZ", result.Tail)
+
+	//-------------------------------------------------------------------------
+
+	var resultOutOfBounds testRootPositionUnmarshal
+	_, err = Unmarshal([]byte("D AB X"), &resultOutOfBounds, EncodingUTF8, TimezoneUTC, "\r")
+
+	var errOutOfBounds *ErrorReadingOutOfBounds
+	assert.Equal(t, true, errors.Is(err, errOutOfBounds))
+}
+
+//
+//-Overlays--------------------------------------------------------------------
+
+type testOverlayUnmarshal struct {
+	Kind    string  `bin:":1"`
+	Raw     string  `bin:":6"`
+	Result  float32 `bin:":6,overlay:Raw,if:Kind=R"`
+	Comment string  `bin:":4,overlay:Raw,if:Kind=C"`
+	Flag    string  `bin:":1"`
+}
+
+type testOverlayWithoutConditionUnmarshal struct {
+	Kind   string `bin:":1"`
+	Raw    string `bin:":6"`
+	Result int    `bin:":6,overlay:Raw"`
+}
+
+func TestUnmarshalOverlay(t *testing.T) {
+
+	var result testOverlayUnmarshal
+	position, err := Unmarshal([]byte("R0001.5F"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 8, position)
+	assert.Equal(t, testOverlayUnmarshal{Kind: "R", Raw: "0001.5", Result: 1.5, Flag: "F"}, result)
+
+	//-------------------------------------------------------------------------
+
+	var resultComment testOverlayUnmarshal
+	position, err = Unmarshal([]byte("CHEMO  F"), &resultComment, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 8, position)
+	assert.Equal(t, testOverlayUnmarshal{Kind: "C", Raw: "HEMO  ", Comment: "HEMO", Flag: "F"}, resultComment)
+
+	//-------------------------------------------------------------------------
+
+	// a number always read from the bytes would fail on a comment
+	var resultWithoutCondition testOverlayWithoutConditionUnmarshal
+	_, err = Unmarshal([]byte("CHEMO  "), &resultWithoutCondition, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorOverlayWithoutCondition))
+
+	var errs = Validate(testOverlayWithoutConditionUnmarshal{})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, true, errors.Is(errs[0], ErrorOverlayWithoutCondition))
+}
+
+//
+//-Checksums-------------------------------------------------------------------
+
+type testChecksumUnmarshal struct {
+	Crc   string `bin:":4,checksum:crc16:5-14"`
+	Start string `bin:":1"`
+	Data  string `bin:":9"`
+	Sum   string `bin:":2,checksum:sum8:5-"`
+	Xor   int    `bin:":3,checksum:xor:5-14"`
+}
+
+func TestUnmarshalChecksum(t *testing.T) {
+
+	var result testChecksumUnmarshal
+	position, err := Unmarshal([]byte("BB3D\x02123456789DD049"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 19, position)
+	assert.Equal(t, "123456789", result.Data)
+	assert.Equal(t, "DD", result.Sum)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Unmarshal([]byte("BB3D\x02123456789DE049"), &result, EncodingUTF8, TimezoneUTC, "\r")
+
+	var errChecksumMismatch *ErrorChecksumMismatch
+	assert.Equal(t, true, errors.As(err, &errChecksumMismatch))
+	assert.Equal(t, uint64(0xDD), errChecksumMismatch.Expected)
+	assert.Equal(t, uint64(0xDE), errChecksumMismatch.Actual)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Unmarshal([]byte("BB3D\x02123456780DD049"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errChecksumMismatch))
+}
+
+//
+//-Length Fields---------------------------------------------------------------
+
+type testLengthBlockUnmarshal struct {
+	Code  string `bin:":2"`
+	Items []int  `bin:"array:fill,:1"`
+}
+
+type testLengthUnmarshal struct {
+	RecordLength int `bin:":3,length:self"`
+	BlockLength  int `bin:":2,length:Block"`
+	Block        testLengthBlockUnmarshal
+	Comment      string `bin:":4"`
+}
+
+type testLengthFixedBlockUnmarshal struct {
+	BlockLength int `bin:":2,length:Block"`
+	Block       struct {
+		Code string `bin:":2"`
+	}
+}
+
+func TestUnmarshalLengthField(t *testing.T) {
+
+	var result testLengthUnmarshal
+	position, err := Unmarshal([]byte("01405AB123DONE"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 14, position)
+	assert.Equal(t, []int{1, 2, 3}, result.Block.Items)
+	assert.Equal(t, "DONE", result.Comment)
+
+	// the block is bounded by its length, so the array doesn't fill the rest of the record
+	var resultBounded testLengthUnmarshal
+	position, err = Unmarshal([]byte("01304AB12DONE"), &resultBounded, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 13, position)
+	assert.Equal(t, []int{1, 2}, resultBounded.Block.Items)
+	assert.Equal(t, "DONE", resultBounded.Comment)
+
+	//-------------------------------------------------------------------------
+
+	_, err = Unmarshal([]byte("01505AB123DONEX"), &result, EncodingUTF8, TimezoneUTC, "\r")
+
+	var errLengthMismatch *ErrorLengthMismatch
+	assert.Equal(t, true, errors.As(err, &errLengthMismatch))
+	assert.Equal(t, 15, errLengthMismatch.Declared)
+	assert.Equal(t, 14, errLengthMismatch.Actual)
+
+	//-------------------------------------------------------------------------
+
+	var resultFixed testLengthFixedBlockUnmarshal
+	_, err = Unmarshal([]byte("03ABC"), &resultFixed, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errLengthMismatch))
+
+	_, err = Unmarshal([]byte("05ABC"), &resultFixed, EncodingUTF8, TimezoneUTC, "\r")
+	var errOutOfBounds *ErrorReadingOutOfBounds
+	assert.Equal(t, true, errors.Is(err, errOutOfBounds))
+
+	//-------------------------------------------------------------------------
+
+	// a negative or too short length gives an error instead of a panic
+	var resultPayload testLengthPayloadUnmarshal
+	_, err = Unmarshal([]byte("-5abc"), &resultPayload, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.As(err, &errLengthMismatch))
+	assert.Equal(t, -5, errLengthMismatch.Declared)
+
+	_, err = Unmarshal([]byte("01abc"), &resultPayload, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, errOutOfBounds))
+}
+
+type testLengthPayloadUnmarshal struct {
+	L       int    `bin:":2,length:Payload"`
+	Payload string `bin:":3"`
+}
+
+//
+//-Separators------------------------------------------------------------------
+
+func TestUnmarshalSeparator(t *testing.T) {
+
+	var result testSeparatorMarshal
+	position, err := Unmarshal([]byte("AA|BB|CC\r1\\2\\32X^YE"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 19, position)
+	assert.Equal(t, []string{"AA", "BB", "CC"}, result.Terminated)
+	assert.Equal(t, []int{1, 2, 3}, result.Fixed)
+	assert.Equal(t, []string{"X", "Y"}, result.Dynamic)
+	assert.Equal(t, "E", result.Trailer)
+
+	//-------------------------------------------------------------------------
+
+	var resultMissing testSeparatorMarshal
+	_, err = Unmarshal([]byte("AA|BB|CC\r1\\232X^YE"), &resultMissing, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorSeparatorNotFound))
+}
+
+//
+//-Terminator Overrides--------------------------------------------------------
+
+func TestUnmarshalTerminatorOverride(t *testing.T) {
+
+	var result testTerminatorMarshal
+	position, err := Unmarshal([]byte("H1AB1CD2\x17xy\x03"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 12, position)
+	assert.Equal(t, "H1", result.Header)
+	assert.Equal(t, []testTerminatorResultMarshal{{Code: "AB", Value: 1}, {Code: "CD", Value: 2}}, result.Results)
+	assert.Equal(t, []string{"x", "y"}, result.Comments)
+
+	//-------------------------------------------------------------------------
+
+	var resultInvalid testTerminatorInvalidMarshal
+	_, err = Unmarshal([]byte("x\r"), &resultInvalid, EncodingUTF8, TimezoneUTC, "\r")
+	var errInvalidTerminator *ErrorInvalidTerminator
+	assert.Equal(t, true, errors.Is(err, errInvalidTerminator))
+
+	var resultMisplaced testTerminatorMisplacedMarshal
+	_, err = Unmarshal([]byte("ABB"), &resultMisplaced, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Equal(t, true, errors.Is(err, ErrorMisplacedStructAnnotation))
+}
+
+//
+//-Default Values--------------------------------------------------------------
+
+type testDefaultUnmarshal struct {
+	UnitNo  int      `bin:":2,default:7,filldefault"`
+	Factor  *float64 `bin:":4,default:1.5,filldefault,null:'    '"`
+	Version string   `bin:":2,trim,default:V2"`
+	Comment string   `bin:":2,optional,default:NA,filldefault"`
+}
+
+func TestUnmarshalDefault(t *testing.T) {
+
+	var result testDefaultUnmarshal
+	position, err := Unmarshal([]byte("00      "), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 8, position)
+	assert.Equal(t, 7, result.UnitNo)
+	assert.Equal(t, 1.5, *result.Factor)
+	assert.Equal(t, "", result.Version) // without 'filldefault' the default is only used by Marshal
+	assert.Equal(t, "NA", result.Comment)
+
+	//-------------------------------------------------------------------------
+
+	var resultSet testDefaultUnmarshal
+	_, err = Unmarshal([]byte("122.25V3OK"), &resultSet, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 12, resultSet.UnitNo)
+	assert.Equal(t, 2.25, *resultSet.Factor)
+	assert.Equal(t, "V3", resultSet.Version)
+	assert.Equal(t, "OK", resultSet.Comment)
+}
+
+//
+//-Tag Keys--------------------------------------------------------------------
+
+func TestUnmarshalWithTagKey(t *testing.T) {
+
+	var result testTagKeyMarshal
+	position, err := Unmarshal([]byte("D  S1042XXAB"), &result, EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 12, position)
+	assert.Equal(t, testTagKeyMarshal{RecordType: "D", SampleId: "  S1", Value: 42, Filler: "XX", Nested: testTagKeyInnerMarshal{Code: "AB"}}, result)
+
+	//-------------------------------------------------------------------------
+
+	var resultV2 testTagKeyMarshal
+	position, err = UnmarshalWithTagKey([]byte("D    S10042H AB"), &resultV2, "bin_v2", EncodingUTF8, TimezoneUTC, "\r")
+	assert.Nil(t, err)
+	assert.Equal(t, 15, position)
+	assert.Equal(t, testTagKeyMarshal{RecordType: "D", SampleId: "    S1", Value: 42, Flag: "H", Nested: testTagKeyInnerMarshal{Code: " AB"}}, resultV2)
+}
@@ -0,0 +1,180 @@
+package binfile
+
+import (
+	"reflect"
+)
+
+// Accepts an annotated struct, a slice of structs or a pointer to them and checks all annotations without any data.
+// This includes the names and values of the annotations, overlapping absolute positions, the referenced
+// array size and flag fields and the field types.
+//
+// Returns every problem found or nil if the annotations are valid. Useful to be called in unit tests.
+func Validate(v interface{}) []error {
+	return ValidateWithTagKey(v, defaultTagKey)
+}
+
+// Works like Validate, but checks the annotations of the tags with the provided key, e.g. "bin_v2".
+// See MarshalWithTagKey.
+func ValidateWithTagKey(v interface{}, tagKey string) []error {
+
+	var recordType = reflect.TypeOf(v)
+	for recordType != nil && (recordType.Kind() == reflect.Ptr || recordType.Kind() == reflect.Slice) {
+		recordType = recordType.Elem()
+	}
+
+	if recordType == nil || recordType.Kind() != reflect.Struct {
+		return []error{newUnsupportedTypeError(reflect.TypeOf(v))}
+	}
+
+	var errs, _, _ = validateStruct(recordType, tagKey, 0, true, map[reflect.Type]bool{})
+	return errs
+}
+
+// Checks the annotations of every field in the struct type recursively. The struct starts at 'startOffset'
+// which is only valid if 'isStartStatic' is true. 'pathTypes' holds the struct types enclosing the current one,
+// so a recursive type is only checked once. See layoutStruct.
+//
+// Returns the errors found, the size of the struct and a bool which is true if the size doesn't depend on the data.
+func validateStruct(recordType reflect.Type, tagKey string, startOffset int, isStartStatic bool, pathTypes map[reflect.Type]bool) ([]error, int, bool) {
+
+	if pathTypes[recordType] {
+		return nil, 0, false
+	}
+	pathTypes[recordType] = true
+	defer delete(pathTypes, recordType)
+
+	var plan = getStructPlan(recordType, tagKey)
+	var errs []error
+	var currentPos = 0
+	var isStatic = true
+
+	for fieldNo := range plan.fields {
+
+		var field = &plan.fields[fieldNo]
+		var structField = recordType.Field(fieldNo)
+
+		for _, annotation := range field.unknownAnnotations {
+			errs = append(errs, newProcessingFieldError(field.name, field.binTag, newUnknownAnnotationError(annotation)))
+		}
+		if field.err != nil {
+			errs = append(errs, field.err)
+			errs = append(errs, field.moreErrs...)
+			if field.hasAnnotatedAddress && field.absoluteAnnotatedPos == -1 && field.valueKind != reflect.Struct && field.valueKind != reflect.Slice {
+				currentPos += field.relativeAnnotatedLength // so the positions of the fields after it are still checked
+			}
+			continue
+		}
+		if !structField.IsExported() && !field.isConstant {
+			continue
+		}
+
+		var fieldErrs []error
+		if _, isRegistered := getChecksumAlgorithm(field.checksumAlgorithm); field.hasChecksum && !isRegistered {
+			fieldErrs = append(fieldErrs, newUnknownChecksumAlgorithmError(field.checksumAlgorithm))
+		}
+
+		if field.absoluteAnnotatedPos != -1 {
+			// a root relative position is only known in the struct if the start of the struct is static
+			var offsetBase, isPositionStatic = 0, true
+			if field.isRootRelative {
+				offsetBase, isPositionStatic = startOffset, isStartStatic
+			}
+			var pointedPos = field.absoluteAnnotatedPos - offsetBase
+			if isStatic && isPositionStatic && pointedPos < currentPos {
+				fieldErrs = append(fieldErrs, newInvalidInvalidOffsetError(offsetBase+currentPos, field.absoluteAnnotatedPos))
+			}
+			currentPos, isStatic = pointedPos, isPositionStatic
+		}
+
+		switch {
+		case field.isConstant:
+
+			currentPos += field.relativeAnnotatedLength
+
+		case field.hasOverlay:
+
+			if !isSupportedSimpleType(structField.Type) {
+				fieldErrs = append(fieldErrs, newUnsupportedTypeError(structField.Type))
+			}
+
+		case field.valueKind == reflect.Struct:
+
+			var nestedErrs, size, isNestedStatic = validateStruct(structField.Type, tagKey, startOffset+currentPos, isStartStatic && isStatic, pathTypes)
+			fieldErrs = append(fieldErrs, nestedErrs...)
+			currentPos += size
+			isStatic = isStatic && isNestedStatic
+
+		case !field.hasAnnotations:
+
+			continue
+
+		case field.hasLenPrefix && (field.valueKind == reflect.String || isByteSliceType(structField.Type)):
+
+			isStatic = false
+
+		case field.valueKind == reflect.Slice:
+
+			var elemType = structField.Type.Elem()
+			var elemSize = field.relativeAnnotatedLength
+			var isElemStatic = true
+			if elemType.Kind() == reflect.Struct {
+				var nestedErrs []error
+				nestedErrs, elemSize, isElemStatic = validateStruct(elemType, tagKey, startOffset+currentPos, isStartStatic && isStatic, pathTypes)
+				fieldErrs = append(fieldErrs, nestedErrs...)
+			} else if !isSupportedSimpleType(elemType) {
+				fieldErrs = append(fieldErrs, newUnsupportedTypeError(elemType))
+			}
+
+			if field.isDynamicSize {
+				var err = validateReferencedField(recordType, field.arraySizeFieldName, reflect.Int)
+				if sizeFieldNo, isFieldFound := plan.fieldNo(field.arraySizeFieldName); err == nil && isFieldFound && sizeFieldNo > fieldNo {
+					var _, isTrailingStatic = sizeOfTrailingFields(recordType, tagKey, sizeFieldNo)
+					if plan.fields[sizeFieldNo].absoluteAnnotatedPos == -1 && !isTrailingStatic {
+						err = ErrorUnknownFieldPosition
+					}
+				}
+				if err != nil {
+					fieldErrs = append(fieldErrs, newInvalidDynamicArraySizeError(recordType.Name(), field.arraySizeFieldName, err))
+				}
+			}
+
+			if field.isFixedSize && isElemStatic {
+				currentPos += field.fixedArraySize(elemSize)
+			} else {
+				isStatic = false
+			}
+
+		default:
+
+			if !isSupportedSimpleType(structField.Type) {
+				fieldErrs = append(fieldErrs, newUnsupportedTypeError(structField.Type))
+			}
+			currentPos += field.relativeAnnotatedLength
+		}
+
+		if field.hasCondition {
+			isStatic = false // the fields after a conditional field have no fixed position
+		}
+
+		for _, err := range fieldErrs {
+			errs = append(errs, newProcessingFieldError(field.name, field.binTag, err))
+		}
+	}
+
+	return errs, currentPos, isStatic
+}
+
+// Checks if the struct type has a field with the provided 'name' and 'kind'.
+// Gives back the same errors as resolving the field with data would.
+func validateReferencedField(recordType reflect.Type, name string, kind reflect.Kind) error {
+
+	var fieldType, isFieldFound = getFieldTypeFromStructType(recordType, name)
+	if !isFieldFound {
+		return ErrorUnknownFieldName
+	}
+	if fieldType.Kind() != kind {
+		return newUnsupportedTypeError(fieldType)
+	}
+
+	return nil
+}
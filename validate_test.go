@@ -0,0 +1,133 @@
+package binfile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+//-Validation------------------------------------------------------------------
+
+type testValidateInvalid struct {
+	Misspelled     string   `bin:":2,trimm"`       // 0
+	AlsoMisspelled int      `bin:":2,padspaces"`   // 2
+	Overlapping    string   `bin:"3:2"`            // 4 - points backwards
+	Unsupported    bool     `bin:":1"`             // 5
+	BadSign        int      `bin:":3,sign:middle"` // 6
+	NotAFlag       int      `bin:":3,null:-,nullflag:Misspelled"`
+	Array          []string `bin:"array:termintor,:2"` // no such size field
+	Nested         struct {
+		InnerMisspelled string `bin:":1,forcesing"`
+	}
+}
+
+type testValidateMistakesInOneTag struct {
+	Misspelled int     `bin:":2,trimm,padspaces,sign:middle"`
+	Invalid    float64 `bin:":5,precision:x,round:sideways,scale:0"`
+}
+
+func TestValidateValidStructures(t *testing.T) {
+
+	assert.Nil(t, Validate(testGeneralStructureMarshal{}))
+	assert.Nil(t, Validate(&testMultipleRecordsUnmarshal{}))
+	assert.Nil(t, Validate([]testTopLevelArrayInnerMarshal{}))
+	assert.Nil(t, Validate(testDynamicArrayMarshal{}))
+	assert.Nil(t, Validate(testRootPositionMarshal{}))
+}
+
+func TestValidateReportsEveryMistake(t *testing.T) {
+
+	var errs = Validate(testValidateInvalid{})
+	assert.Equal(t, 8, len(errs))
+
+	var errUnknownAnnotation *ErrorUnknownAnnotation
+	var errInvalidOffset *ErrorInvalidOffset
+	var errUnsupportedType *ErrorUnsupportedType
+	var errInvalidSign *ErrorInvalidSignConvention
+	var errInvalidDynamicArraySize *ErrorInvalidDynamicArraySize
+
+	assert.Equal(t, true, errors.Is(errs[0], errUnknownAnnotation))
+	assert.Equal(t, true, errors.Is(errs[1], errUnknownAnnotation))
+	assert.Equal(t, true, errors.Is(errs[2], errInvalidOffset))
+	assert.Equal(t, true, errors.Is(errs[3], errUnsupportedType))
+	assert.Equal(t, true, errors.Is(errs[4], errInvalidSign))
+	assert.Equal(t, true, errors.Is(errs[5], errUnsupportedType))
+	assert.Equal(t, true, errors.Is(errs[6], errInvalidDynamicArraySize))
+	assert.Equal(t, true, errors.Is(errs[6], ErrorUnknownFieldName))
+	assert.Equal(t, true, errors.Is(errs[7], errUnknownAnnotation))
+
+	//-------------------------------------------------------------------------
+
+	// every mistake in a tag is reported, not only the first one
+	errs = Validate(testValidateMistakesInOneTag{})
+	assert.Equal(t, 6, len(errs))
+
+	var errInvalidPrecision *ErrorInvalidPrecision
+	var errInvalidRoundingMode *ErrorInvalidRoundingMode
+	var errInvalidScaling *ErrorInvalidScaling
+
+	assert.Equal(t, true, errors.Is(errs[0], errUnknownAnnotation))
+	assert.Equal(t, true, errors.Is(errs[1], errUnknownAnnotation))
+	assert.Equal(t, true, errors.Is(errs[2], errInvalidSign))
+	assert.Equal(t, true, errors.Is(errs[3], errInvalidPrecision))
+	assert.Equal(t, true, errors.Is(errs[4], errInvalidRoundingMode))
+	assert.Equal(t, true, errors.Is(errs[5], errInvalidScaling))
+
+	//-------------------------------------------------------------------------
+
+	errs = Validate(testRootPositionBackwardsMarshal{})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, true, errors.Is(errs[0], errInvalidOffset))
+
+	//-------------------------------------------------------------------------
+
+	errs = Validate(testTerminatorMisplacedMarshal{})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, true, errors.Is(errs[0], ErrorMisplacedStructAnnotation))
+
+	//-------------------------------------------------------------------------
+
+	errs = Validate(42)
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, true, errors.Is(errs[0], errUnsupportedType))
+}
+
+type testValidateForwardReference struct {
+	Items []int `bin:"array:Count,:1"`
+	Count int   `bin:":1"` // can't be found from the end of the record
+	Rest  []int `bin:"array:terminator,:1"`
+}
+
+func TestValidateForwardReference(t *testing.T) {
+
+	assert.Nil(t, Validate(testForwardReferenceUnmarshal{}))
+	assert.Nil(t, Validate(testForwardReferenceAbsoluteUnmarshal{}))
+
+	var errs = Validate(testValidateForwardReference{})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, true, errors.Is(errs[0], ErrorUnknownFieldPosition))
+}
+
+type testValidateTagKey struct {
+	RecordType string `bin:":1"`
+	Value      int    `bin:":3" bin_v2:":4,padspaces"` // only mistyped in the second layout
+}
+
+func TestValidateWithTagKey(t *testing.T) {
+
+	assert.Nil(t, ValidateWithTagKey(testTagKeyMarshal{}, "bin_v2"))
+	assert.Nil(t, Validate(testValidateTagKey{}))
+
+	var errs = ValidateWithTagKey(testValidateTagKey{}, "bin_v2")
+	assert.Equal(t, 1, len(errs))
+	var errUnknownAnnotation *ErrorUnknownAnnotation
+	assert.Equal(t, true, errors.Is(errs[0], errUnknownAnnotation))
+}
+
+func TestValidateRecursiveType(t *testing.T) {
+
+	// a type containing itself is only checked once
+	assert.Nil(t, Validate(testCodecRecursive{}))
+}